@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -14,6 +15,14 @@ type TeamGroupMember struct {
 	Permission string `json:"permission"`
 }
 
+type RepoBaseline struct {
+	TeamGroup        string                 `json:"team_group"`
+	Topics           []string               `json:"topics"`
+	CustomProperties map[string]interface{} `json:"custom_properties"`
+	RulesetTemplate  string                 `json:"ruleset_template"`
+	RulesetParams    *RulesetTemplateParams `json:"ruleset_params"`
+}
+
 type Config struct {
 	GitHubToken  string                                  `json:"github_token"`
 	SelectedOrg  string                                  `json:"selected_org"`
@@ -26,6 +35,7 @@ type Config struct {
 	Theme        string                                  `json:"theme"`
 	RepoGroups   map[string]map[string][]string          `json:"repo_groups"` // Org -> GroupName -> []RepoFullName
 	TeamGroups   map[string]map[string][]TeamGroupMember `json:"team_groups"` // Org -> GroupName -> []TeamGroupMember
+	Baselines    map[string]map[string]*RepoBaseline     `json:"baselines"`   // Org -> BaselineName -> RepoBaseline
 }
 
 type AppConfigService struct{}
@@ -101,6 +111,9 @@ func LoadConfig() (*Config, error) {
 	if cfg.TeamGroups == nil {
 		cfg.TeamGroups = make(map[string]map[string][]TeamGroupMember)
 	}
+	if cfg.Baselines == nil {
+		cfg.Baselines = make(map[string]map[string]*RepoBaseline)
+	}
 	return cfg, nil
 }
 
@@ -115,3 +128,15 @@ func SaveConfig(cfg *Config) error {
 	}
 	return os.WriteFile(configPath, data, 0644)
 }
+
+func repoGroupRepos(org, group string) ([]string, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	repos, ok := cfg.RepoGroups[org][group]
+	if !ok {
+		return nil, fmt.Errorf("repo group %q not found", group)
+	}
+	return repos, nil
+}
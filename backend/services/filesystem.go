@@ -2,6 +2,8 @@ package services
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
@@ -21,3 +23,19 @@ func (fs *FilesystemCommands) PathExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// SaveTextFile asks for a destination and writes content to it. It returns
+// the chosen path, or an empty string when the dialog was cancelled.
+func (fs *FilesystemCommands) SaveTextFile(defaultName, content string) (string, error) {
+	dialog := application.Get().Dialog.SaveFile().
+		CanCreateDirectories(true).
+		SetFilename(defaultName)
+	if ext := filepath.Ext(defaultName); ext != "" {
+		dialog.AddFilter(strings.ToUpper(ext[1:])+" files", "*"+ext)
+	}
+	path, err := dialog.PromptForSingleSelection()
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type AccessGrant struct {
+	Source     string `json:"source"` // "owner", "base", "team", "direct" or "org_owner"
+	Path       string `json:"path"`   // How the grant reaches the user, e.g. "team: platform > backend"
+	Permission string `json:"permission"`
+}
+
+type UserAccess struct {
+	Login      string         `json:"login"`
+	Permission string         `json:"permission"` // Highest permission across all grants
+	Grants     []*AccessGrant `json:"grants"`
+}
+
+type RepoAccessReport struct {
+	Repo           string        `json:"repo"`
+	BasePermission string        `json:"base_permission"`
+	Users          []*UserAccess `json:"users"`
+	Warnings       []string      `json:"warnings"`
+}
+
+// GetRepoAccess resolves who can access a repository and through which
+// grants: the org base permission, org ownership, team grants (including
+// members of child teams) and direct collaborator grants.
+func (ghs *GitHubService) GetRepoAccess(owner, repo string) (*RepoAccessReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	report := &RepoAccessReport{Repo: owner + "/" + repo}
+	users := make(map[string]*UserAccess)
+	grant := func(login string, g *AccessGrant) {
+		u, ok := users[login]
+		if !ok {
+			u = &UserAccess{Login: login}
+			users[login] = u
+		}
+		u.Grants = append(u.Grants, g)
+		if accessRank(g.Permission) > accessRank(u.Permission) {
+			u.Permission = g.Permission
+		}
+	}
+
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.GetOwner().GetType() == "Organization" {
+		org, _, err := ghs.client.Organizations.Get(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		report.BasePermission = normalizePermission(org.GetDefaultRepoPermission())
+
+		admins, err := ghs.listOrgMembers(ctx, owner, "admin", "")
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list org owners: %v", err))
+		}
+		for _, u := range admins {
+			grant(u.GetLogin(), &AccessGrant{Source: "org_owner", Path: "owner of " + owner, Permission: "admin"})
+		}
+
+		if report.BasePermission != "" && report.BasePermission != "none" {
+			members, err := ghs.listOrgMembers(ctx, owner, "all", "")
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list org members: %v", err))
+			}
+			for _, u := range members {
+				grant(u.GetLogin(), &AccessGrant{Source: "base", Path: "member of " + owner, Permission: report.BasePermission})
+			}
+		}
+
+		teams, err := ghs.listRepoTeams(ctx, owner, repo)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list teams: %v", err))
+		}
+		for _, t := range teams {
+			permission := normalizePermission(t.GetPermission())
+			paths, err := ghs.teamMemberPaths(ctx, owner, t)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list members of %s: %v", t.GetSlug(), err))
+				continue
+			}
+			for login, path := range paths {
+				grant(login, &AccessGrant{Source: "team", Path: "team: " + path, Permission: permission})
+			}
+		}
+	} else {
+		grant(owner, &AccessGrant{Source: "owner", Path: "repository owner", Permission: "admin"})
+	}
+
+	direct, err := ghs.listCollaboratorUsers(ctx, owner, repo, "direct")
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list collaborators: %v", err))
+	}
+	for _, u := range direct {
+		grant(u.GetLogin(), &AccessGrant{Source: "direct", Path: "direct collaborator", Permission: normalizePermission(collaboratorPermission(u))})
+	}
+
+	for _, u := range users {
+		report.Users = append(report.Users, u)
+	}
+	sort.Slice(report.Users, func(i, j int) bool { return report.Users[i].Login < report.Users[j].Login })
+	return report, nil
+}
+
+// ExportRepoAccessCSV returns the access report of a repository as CSV, one
+// row per user and grant.
+func (ghs *GitHubService) ExportRepoAccessCSV(owner, repo string) (string, error) {
+	report, err := ghs.GetRepoAccess(owner, repo)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"repository", "login", "effective_permission", "source", "path", "permission"})
+	for _, u := range report.Users {
+		for _, g := range u.Grants {
+			w.Write([]string{report.Repo, u.Login, u.Permission, g.Source, g.Path, g.Permission})
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// teamMemberPaths maps every member of team, including members of its child
+// teams, to the team path through which they inherit the team's grant. Users
+// are attributed to the deepest team they belong to.
+func (ghs *GitHubService) teamMemberPaths(ctx context.Context, org string, team *github.Team) (map[string]string, error) {
+	type node struct {
+		slug string
+		path string
+	}
+	paths := make(map[string]string)
+	queue := []node{{slug: team.GetSlug(), path: team.GetSlug()}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		// Team member lists include members of child teams, so deeper teams
+		// visited later overwrite the path.
+		members, err := ghs.listTeamMembers(ctx, org, n.slug)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			paths[m.GetLogin()] = n.path
+		}
+		children, err := ghs.listChildTeams(ctx, org, n.slug)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range children {
+			queue = append(queue, node{slug: c.GetSlug(), path: n.path + " > " + c.GetSlug()})
+		}
+	}
+	return paths, nil
+}
+
+func (ghs *GitHubService) listOrgMembers(ctx context.Context, org, role, filter string) ([]*github.User, error) {
+	opt := &github.ListMembersOptions{Role: role, Filter: filter, ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.User
+	for {
+		users, resp, err := ghs.client.Organizations.ListMembers(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, users...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) listRepoTeams(ctx context.Context, owner, repo string) ([]*github.Team, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.Team
+	for {
+		teams, resp, err := ghs.client.Repositories.ListTeams(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, teams...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) listTeamMembers(ctx context.Context, org, slug string) ([]*github.User, error) {
+	opt := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.User
+	for {
+		users, resp, err := ghs.client.Teams.ListTeamMembersBySlug(ctx, org, slug, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, users...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) listChildTeams(ctx context.Context, org, slug string) ([]*github.Team, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.Team
+	for {
+		teams, resp, err := ghs.client.Teams.ListChildTeamsByParentSlug(ctx, org, slug, opt)
+		if err != nil {
+			// Teams without children may answer 404 on some GitHub versions.
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return all, nil
+			}
+			return nil, err
+		}
+		all = append(all, teams...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// normalizePermission maps the API's legacy permission names to the role
+// names shown in the GitHub UI.
+func normalizePermission(permission string) string {
+	switch strings.ToLower(permission) {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	}
+	return strings.ToLower(permission)
+}
+
+// accessRank ranks a permission for comparison. Custom repository roles are
+// unknown here and rank as read.
+func accessRank(permission string) int {
+	if permission == "" || permission == "none" {
+		return 0
+	}
+	if rank, ok := teamPermissionRank[permission]; ok {
+		return rank
+	}
+	return teamPermissionRank["read"]
+}
+
+func (ghs *GitHubService) listOrgTeams(ctx context.Context, org string) ([]*github.Team, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.Team
+	for {
+		teams, resp, err := ghs.client.Teams.ListTeams(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, teams...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) listTeamRepos(ctx context.Context, org, slug string) ([]*github.Repository, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.Repository
+	for {
+		repos, resp, err := ghs.client.Teams.ListTeamReposBySlug(ctx, org, slug, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, repos...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestNormalizePermission(t *testing.T) {
+	tests := map[string]string{
+		"pull":      "read",
+		"push":      "write",
+		"PUSH":      "write",
+		"triage":    "triage",
+		"maintain":  "maintain",
+		"admin":     "admin",
+		"custom-ci": "custom-ci",
+		"":          "",
+	}
+	for in, want := range tests {
+		if got := normalizePermission(in); got != want {
+			t.Errorf("normalizePermission(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAccessRank(t *testing.T) {
+	ordered := []string{"none", "read", "triage", "write", "maintain", "admin"}
+	for i := 1; i < len(ordered); i++ {
+		if accessRank(ordered[i-1]) >= accessRank(ordered[i]) {
+			t.Errorf("accessRank(%q) >= accessRank(%q)", ordered[i-1], ordered[i])
+		}
+	}
+	if accessRank("") != 0 {
+		t.Errorf("accessRank(\"\") = %d, want 0", accessRank(""))
+	}
+	if accessRank("custom-ci") != accessRank("read") {
+		t.Errorf("custom role ranks %d, want read rank %d", accessRank("custom-ci"), accessRank("read"))
+	}
+}
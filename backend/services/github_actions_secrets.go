@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+type GitHubActionsSecret struct {
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Visibility string    `json:"visibility"` // Org secrets only: "all", "private" or "selected"
+}
+
+type GitHubActionsVariable struct {
+	Name       string    `json:"name"`
+	Value      string    `json:"value"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Visibility string    `json:"visibility"` // Org variables only
+}
+
+type ActionsSecretResult struct {
+	Repo    string `json:"repo"`
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+type ActionsSecretLocation struct {
+	Repo      string    `json:"repo"` // Empty for the org-level secret
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ActionsSecretUsage struct {
+	Name      string                   `json:"name"`
+	Locations []*ActionsSecretLocation `json:"locations"`
+}
+
+type ActionsSecretsReport struct {
+	Org      string                `json:"org"`
+	Secrets  []*ActionsSecretUsage `json:"secrets"`
+	Warnings []string              `json:"warnings"`
+}
+
+// actionsScope addresses secrets and variables of an org when repo is empty,
+// of a repository, or of one of its environments when env is set.
+type actionsScope struct {
+	owner  string
+	repo   string
+	env    string
+	repoID int
+}
+
+func (ghs *GitHubService) ListActionsSecrets(owner, repo, environment string) ([]*GitHubActionsSecret, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return nil, err
+	}
+	return ghs.listActionsSecrets(ctx, scope)
+}
+
+// SetActionsSecret creates or updates a secret, encrypting the value with the
+// public key of the org, repository or environment. Org secrets keep their
+// current visibility and selected repositories; new ones are created with
+// "private" visibility.
+func (ghs *GitHubService) SetActionsSecret(owner, repo, environment, name, value string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	if name == "" {
+		return fmt.Errorf("secret name is required")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return err
+	}
+	return ghs.setActionsSecret(ctx, scope, name, value)
+}
+
+func (ghs *GitHubService) DeleteActionsSecret(owner, repo, environment, name string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return err
+	}
+	return ghs.deleteActionsSecret(ctx, scope, name)
+}
+
+func (ghs *GitHubService) ListActionsVariables(owner, repo, environment string) ([]*GitHubActionsVariable, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return nil, err
+	}
+	opt := &github.ListOptions{PerPage: 30}
+	var all []*GitHubActionsVariable
+	for {
+		var vars *github.ActionsVariables
+		var resp *github.Response
+		switch {
+		case scope.repo == "":
+			vars, resp, err = ghs.client.Actions.ListOrgVariables(ctx, scope.owner, opt)
+		case scope.env != "":
+			vars, resp, err = ghs.client.Actions.ListEnvVariables(ctx, scope.owner, scope.repo, scope.env, opt)
+		default:
+			vars, resp, err = ghs.client.Actions.ListRepoVariables(ctx, scope.owner, scope.repo, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars.Variables {
+			all = append(all, &GitHubActionsVariable{
+				Name:       v.Name,
+				Value:      v.Value,
+				CreatedAt:  v.GetCreatedAt().Time,
+				UpdatedAt:  v.GetUpdatedAt().Time,
+				Visibility: v.GetVisibility(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// SetActionsVariable creates the variable or updates its value. Org variables
+// keep their current visibility; new ones are created with "private"
+// visibility.
+func (ghs *GitHubService) SetActionsVariable(owner, repo, environment, name, value string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	if name == "" {
+		return fmt.Errorf("variable name is required")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return err
+	}
+	return ghs.setActionsVariable(ctx, scope, name, value)
+}
+
+func (ghs *GitHubService) DeleteActionsVariable(owner, repo, environment, name string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	scope, err := ghs.actionsScope(ctx, owner, repo, environment)
+	if err != nil {
+		return err
+	}
+	return ghs.deleteActionsVariable(ctx, scope, name)
+}
+
+// BulkSetActionsSecret sets the secret on every repository of a repo group,
+// or on the named environment of each when environment is set.
+func (ghs *GitHubService) BulkSetActionsSecret(org, group, environment, name, value string) ([]*ActionsSecretResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("secret name is required")
+	}
+	return ghs.bulkActionsScope(org, group, environment, name, func(ctx context.Context, scope *actionsScope) error {
+		return ghs.setActionsSecret(ctx, scope, name, value)
+	})
+}
+
+func (ghs *GitHubService) BulkDeleteActionsSecret(org, group, environment, name string) ([]*ActionsSecretResult, error) {
+	return ghs.bulkActionsScope(org, group, environment, name, func(ctx context.Context, scope *actionsScope) error {
+		return ghs.deleteActionsSecret(ctx, scope, name)
+	})
+}
+
+func (ghs *GitHubService) BulkSetActionsVariable(org, group, environment, name, value string) ([]*ActionsSecretResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("variable name is required")
+	}
+	return ghs.bulkActionsScope(org, group, environment, name, func(ctx context.Context, scope *actionsScope) error {
+		return ghs.setActionsVariable(ctx, scope, name, value)
+	})
+}
+
+func (ghs *GitHubService) BulkDeleteActionsVariable(org, group, environment, name string) ([]*ActionsSecretResult, error) {
+	return ghs.bulkActionsScope(org, group, environment, name, func(ctx context.Context, scope *actionsScope) error {
+		return ghs.deleteActionsVariable(ctx, scope, name)
+	})
+}
+
+// GetActionsSecretsReport lists every secret name defined on the org or its
+// repositories, with where it is defined and when each copy was last
+// updated. Environment secrets aren't included.
+func (ghs *GitHubService) GetActionsSecretsReport(org string) (*ActionsSecretsReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	report := &ActionsSecretsReport{Org: org}
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]*ActionsSecretUsage)
+	add := func(repo string, secrets []*GitHubActionsSecret) {
+		for _, s := range secrets {
+			u, ok := usage[s.Name]
+			if !ok {
+				u = &ActionsSecretUsage{Name: s.Name}
+				usage[s.Name] = u
+			}
+			u.Locations = append(u.Locations, &ActionsSecretLocation{Repo: repo, UpdatedAt: s.UpdatedAt})
+		}
+	}
+	if secrets, err := ghs.listActionsSecrets(ctx, &actionsScope{owner: org}); err == nil {
+		add("", secrets)
+	} else {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list org secrets: %v", err))
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for _, r := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			secrets, err := ghs.listActionsSecrets(ctx, &actionsScope{owner: org, repo: repo.GetName()})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", repo.GetFullName(), err))
+				return
+			}
+			add(repo.GetFullName(), secrets)
+		}(r)
+	}
+	wg.Wait()
+
+	for _, u := range usage {
+		sort.Slice(u.Locations, func(i, j int) bool { return u.Locations[i].Repo < u.Locations[j].Repo })
+		report.Secrets = append(report.Secrets, u)
+	}
+	sort.Slice(report.Secrets, func(i, j int) bool { return report.Secrets[i].Name < report.Secrets[j].Name })
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+func (ghs *GitHubService) actionsScope(ctx context.Context, owner, repo, environment string) (*actionsScope, error) {
+	scope := &actionsScope{owner: owner, repo: repo, env: environment}
+	if environment == "" {
+		return scope, nil
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("environment requires a repository")
+	}
+	// The environment secret endpoints address the repository by ID.
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	scope.repoID = int(r.GetID())
+	return scope, nil
+}
+
+func (ghs *GitHubService) bulkActionsScope(org, group, environment, name string, apply func(ctx context.Context, scope *actionsScope) error) ([]*ActionsSecretResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	repos, err := repoGroupRepos(org, group)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	var results []*ActionsSecretResult
+	for _, fullRepo := range repos {
+		result := &ActionsSecretResult{Repo: fullRepo, Name: name}
+		results = append(results, result)
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			result.Error = "invalid repository name"
+			continue
+		}
+		scope, err := ghs.actionsScope(ctx, parts[0], parts[1], environment)
+		if err == nil {
+			err = apply(ctx, scope)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Success = true
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) listActionsSecrets(ctx context.Context, scope *actionsScope) ([]*GitHubActionsSecret, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*GitHubActionsSecret
+	for {
+		var secrets *github.Secrets
+		var resp *github.Response
+		var err error
+		switch {
+		case scope.repo == "":
+			secrets, resp, err = ghs.client.Actions.ListOrgSecrets(ctx, scope.owner, opt)
+		case scope.env != "":
+			secrets, resp, err = ghs.client.Actions.ListEnvSecrets(ctx, scope.repoID, scope.env, opt)
+		default:
+			secrets, resp, err = ghs.client.Actions.ListRepoSecrets(ctx, scope.owner, scope.repo, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range secrets.Secrets {
+			all = append(all, &GitHubActionsSecret{
+				Name:       s.Name,
+				CreatedAt:  s.CreatedAt.Time,
+				UpdatedAt:  s.UpdatedAt.Time,
+				Visibility: s.Visibility,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) setActionsSecret(ctx context.Context, scope *actionsScope, name, value string) error {
+	var key *github.PublicKey
+	var err error
+	switch {
+	case scope.repo == "":
+		key, _, err = ghs.client.Actions.GetOrgPublicKey(ctx, scope.owner)
+	case scope.env != "":
+		key, _, err = ghs.client.Actions.GetEnvPublicKey(ctx, scope.repoID, scope.env)
+	default:
+		key, _, err = ghs.client.Actions.GetRepoPublicKey(ctx, scope.owner, scope.repo)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get public key: %w", err)
+	}
+	secret, err := encryptSecret(key, name, value)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case scope.repo == "":
+		existing, resp, err := ghs.client.Actions.GetOrgSecret(ctx, scope.owner, name)
+		switch {
+		case err == nil:
+			secret.Visibility = existing.Visibility
+		case resp != nil && resp.StatusCode == http.StatusNotFound:
+			secret.Visibility = "private"
+		default:
+			return err
+		}
+		if secret.Visibility == "selected" {
+			ids, err := ghs.selectedSecretRepoIDs(ctx, scope.owner, name)
+			if err != nil {
+				return err
+			}
+			secret.SelectedRepositoryIDs = ids
+		}
+		_, err = ghs.client.Actions.CreateOrUpdateOrgSecret(ctx, scope.owner, secret)
+		return err
+	case scope.env != "":
+		_, err = ghs.client.Actions.CreateOrUpdateEnvSecret(ctx, scope.repoID, scope.env, secret)
+		return err
+	default:
+		_, err = ghs.client.Actions.CreateOrUpdateRepoSecret(ctx, scope.owner, scope.repo, secret)
+		return err
+	}
+}
+
+func (ghs *GitHubService) deleteActionsSecret(ctx context.Context, scope *actionsScope, name string) error {
+	var err error
+	switch {
+	case scope.repo == "":
+		_, err = ghs.client.Actions.DeleteOrgSecret(ctx, scope.owner, name)
+	case scope.env != "":
+		_, err = ghs.client.Actions.DeleteEnvSecret(ctx, scope.repoID, scope.env, name)
+	default:
+		_, err = ghs.client.Actions.DeleteRepoSecret(ctx, scope.owner, scope.repo, name)
+	}
+	return err
+}
+
+func (ghs *GitHubService) selectedSecretRepoIDs(ctx context.Context, org, name string) (github.SelectedRepoIDs, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var ids github.SelectedRepoIDs
+	for {
+		list, resp, err := ghs.client.Actions.ListSelectedReposForOrgSecret(ctx, org, name, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list.Repositories {
+			ids = append(ids, r.GetID())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return ids, nil
+}
+
+func (ghs *GitHubService) setActionsVariable(ctx context.Context, scope *actionsScope, name, value string) error {
+	variable := &github.ActionsVariable{Name: name, Value: value}
+	var resp *github.Response
+	var err error
+	switch {
+	case scope.repo == "":
+		_, resp, err = ghs.client.Actions.GetOrgVariable(ctx, scope.owner, name)
+	case scope.env != "":
+		_, resp, err = ghs.client.Actions.GetEnvVariable(ctx, scope.owner, scope.repo, scope.env, name)
+	default:
+		_, resp, err = ghs.client.Actions.GetRepoVariable(ctx, scope.owner, scope.repo, name)
+	}
+	exists := err == nil
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return err
+	}
+
+	switch {
+	case scope.repo == "" && exists:
+		_, err = ghs.client.Actions.UpdateOrgVariable(ctx, scope.owner, variable)
+	case scope.repo == "":
+		variable.Visibility = github.Ptr("private")
+		_, err = ghs.client.Actions.CreateOrgVariable(ctx, scope.owner, variable)
+	case scope.env != "" && exists:
+		_, err = ghs.client.Actions.UpdateEnvVariable(ctx, scope.owner, scope.repo, scope.env, variable)
+	case scope.env != "":
+		_, err = ghs.client.Actions.CreateEnvVariable(ctx, scope.owner, scope.repo, scope.env, variable)
+	case exists:
+		_, err = ghs.client.Actions.UpdateRepoVariable(ctx, scope.owner, scope.repo, variable)
+	default:
+		_, err = ghs.client.Actions.CreateRepoVariable(ctx, scope.owner, scope.repo, variable)
+	}
+	return err
+}
+
+func (ghs *GitHubService) deleteActionsVariable(ctx context.Context, scope *actionsScope, name string) error {
+	var err error
+	switch {
+	case scope.repo == "":
+		_, err = ghs.client.Actions.DeleteOrgVariable(ctx, scope.owner, name)
+	case scope.env != "":
+		_, err = ghs.client.Actions.DeleteEnvVariable(ctx, scope.owner, scope.repo, scope.env, name)
+	default:
+		_, err = ghs.client.Actions.DeleteRepoVariable(ctx, scope.owner, scope.repo, name)
+	}
+	return err
+}
+
+// encryptSecret seals the value for the given public key with a libsodium
+// sealed box, the format the Actions secrets API expects.
+func encryptSecret(key *github.PublicKey, name, value string) (*github.EncryptedSecret, error) {
+	raw, err := base64.StdEncoding.DecodeString(key.GetKey())
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("invalid public key length %d", len(raw))
+	}
+	var pk [32]byte
+	copy(pk[:], raw)
+	sealed, err := box.SealAnonymous(nil, []byte(value), &pk, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &github.EncryptedSecret{
+		Name:           name,
+		KeyID:          key.GetKeyID(),
+		EncryptedValue: base64.StdEncoding.EncodeToString(sealed),
+	}, nil
+}
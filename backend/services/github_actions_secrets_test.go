@@ -0,0 +1,48 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &github.PublicKey{
+		KeyID: github.Ptr("568250167242549743"),
+		Key:   github.Ptr(base64.StdEncoding.EncodeToString(pub[:])),
+	}
+
+	secret, err := encryptSecret(key, "REGISTRY_TOKEN", "s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Name != "REGISTRY_TOKEN" || secret.KeyID != "568250167242549743" {
+		t.Errorf("got name %q key id %q", secret.Name, secret.KeyID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(secret.EncryptedValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened, ok := box.OpenAnonymous(nil, sealed, pub, priv)
+	if !ok {
+		t.Fatal("sealed value can't be opened with the private key")
+	}
+	if string(opened) != "s3cr3t" {
+		t.Errorf("decrypted %q, want %q", opened, "s3cr3t")
+	}
+}
+
+func TestEncryptSecretInvalidKey(t *testing.T) {
+	for _, k := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := encryptSecret(&github.PublicKey{KeyID: github.Ptr("1"), Key: github.Ptr(k)}, "NAME", "value"); err == nil {
+			t.Errorf("key %q: expected an error", k)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// GitHubActionsSettings holds the Actions policy of a repository or an org.
+// Enabled applies to repositories and EnabledRepositories to orgs. Nil fields
+// are left unchanged on update.
+type GitHubActionsSettings struct {
+	Enabled                      *bool    `json:"enabled"`
+	EnabledRepositories          *string  `json:"enabled_repositories"`         // "all", "none" or "selected"
+	AllowedActions               *string  `json:"allowed_actions"`              // "all", "local_only" or "selected"
+	GithubOwnedAllowed           *bool    `json:"github_owned_allowed"`         // Only with allowed_actions "selected"
+	VerifiedAllowed              *bool    `json:"verified_allowed"`             // Only with allowed_actions "selected"
+	PatternsAllowed              []string `json:"patterns_allowed"`             // Only with allowed_actions "selected"
+	DefaultWorkflowPermissions   *string  `json:"default_workflow_permissions"` // "read" or "write"
+	CanApprovePullRequestReviews *bool    `json:"can_approve_pull_request_reviews"`
+	ForkPRApprovalPolicy         *string  `json:"fork_pr_approval_policy"` // "first_time_contributors_new_to_github", "first_time_contributors" or "all_external_contributors"
+}
+
+func (ghs *GitHubService) GetRepoActionsSettings(owner, repo string) (*GitHubActionsSettings, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.repoActionsSettings(context.Background(), owner, repo)
+}
+
+func (ghs *GitHubService) UpdateRepoActionsSettings(owner, repo string, settings *GitHubActionsSettings) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	result := ghs.updateRepoActionsSettings(context.Background(), owner, repo, settings, false)
+	if result.Error != "" {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// BulkUpdateRepoActionsSettings applies the same Actions policy to many
+// repositories. With dryRun nothing is written and each result lists the
+// changes that would be made.
+func (ghs *GitHubService) BulkUpdateRepoActionsSettings(fullRepos []string, settings *GitHubActionsSettings, dryRun bool) ([]*RepoSettingsResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*RepoSettingsResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &RepoSettingsResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		results = append(results, ghs.updateRepoActionsSettings(ctx, parts[0], parts[1], settings, dryRun))
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) GetOrgActionsSettings(org string) (*GitHubActionsSettings, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.orgActionsSettings(context.Background(), org)
+}
+
+// UpdateOrgActionsSettings applies the Actions policy to the org. With dryRun
+// nothing is written and the result lists the changes that would be made.
+func (ghs *GitHubService) UpdateOrgActionsSettings(org string, settings *GitHubActionsSettings, dryRun bool) (*RepoSettingsResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if settings == nil {
+		return nil, fmt.Errorf("no settings given")
+	}
+	ctx := context.Background()
+	result := &RepoSettingsResult{Repo: org}
+	current, err := ghs.orgActionsSettings(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateActionsSettings(current, settings, true); err != nil {
+		return nil, err
+	}
+	result.Changes = diffActionsSettings(current, settings)
+	if dryRun || len(result.Changes) == 0 {
+		return result, nil
+	}
+
+	if settings.EnabledRepositories != nil || settings.AllowedActions != nil {
+		_, _, err = ghs.client.Actions.UpdateActionsPermissions(ctx, org, github.ActionsPermissions{
+			EnabledRepositories: orCurrent(settings.EnabledRepositories, current.EnabledRepositories),
+			AllowedActions:      settings.AllowedActions,
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update permissions: %v", err)
+			return result, nil
+		}
+	}
+	if allowed := actionsAllowedUpdate(current, settings); allowed != nil {
+		if _, _, err := ghs.client.Actions.UpdateActionsAllowed(ctx, org, *allowed); err != nil {
+			result.Error = fmt.Sprintf("failed to update allowed actions: %v", err)
+			return result, nil
+		}
+	}
+	if settings.DefaultWorkflowPermissions != nil || settings.CanApprovePullRequestReviews != nil {
+		_, _, err = ghs.client.Actions.UpdateDefaultWorkflowPermissionsInOrganization(ctx, org, github.DefaultWorkflowPermissionOrganization{
+			DefaultWorkflowPermissions:   settings.DefaultWorkflowPermissions,
+			CanApprovePullRequestReviews: settings.CanApprovePullRequestReviews,
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update workflow permissions: %v", err)
+			return result, nil
+		}
+	}
+	if settings.ForkPRApprovalPolicy != nil {
+		_, err = ghs.client.Actions.UpdateOrganizationForkPRContributorApprovalPermissions(ctx, org, github.ContributorApprovalPermissions{ApprovalPolicy: *settings.ForkPRApprovalPolicy})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update fork pull request approval: %v", err)
+			return result, nil
+		}
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// repoActionsSettings reads the Actions policy of a repository. The fork
+// approval policy is left nil when it can't be read, as for a private
+// repository.
+func (ghs *GitHubService) repoActionsSettings(ctx context.Context, owner, repo string) (*GitHubActionsSettings, error) {
+	perms, _, err := ghs.client.Repositories.GetActionsPermissions(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	settings := &GitHubActionsSettings{
+		Enabled:        perms.Enabled,
+		AllowedActions: perms.AllowedActions,
+	}
+	if perms.GetAllowedActions() == "selected" {
+		allowed, _, err := ghs.client.Repositories.GetActionsAllowed(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allowed actions: %w", err)
+		}
+		settings.GithubOwnedAllowed = allowed.GithubOwnedAllowed
+		settings.VerifiedAllowed = allowed.VerifiedAllowed
+		settings.PatternsAllowed = allowed.PatternsAllowed
+	}
+	wf, resp, err := ghs.client.Repositories.GetDefaultWorkflowPermissions(ctx, owner, repo)
+	if err == nil {
+		settings.DefaultWorkflowPermissions = wf.DefaultWorkflowPermissions
+		settings.CanApprovePullRequestReviews = wf.CanApprovePullRequestReviews
+	} else if !actionsSettingNotApplicable(resp) {
+		return nil, fmt.Errorf("failed to get workflow permissions: %w", err)
+	}
+	approval, resp, err := ghs.client.Actions.GetForkPRContributorApprovalPermissions(ctx, owner, repo)
+	if err == nil {
+		settings.ForkPRApprovalPolicy = github.Ptr(approval.ApprovalPolicy)
+	} else if !actionsSettingNotApplicable(resp) {
+		return nil, fmt.Errorf("failed to get fork pull request approval policy: %w", err)
+	}
+	return settings, nil
+}
+
+func (ghs *GitHubService) orgActionsSettings(ctx context.Context, org string) (*GitHubActionsSettings, error) {
+	perms, _, err := ghs.client.Actions.GetActionsPermissions(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	settings := &GitHubActionsSettings{
+		EnabledRepositories: perms.EnabledRepositories,
+		AllowedActions:      perms.AllowedActions,
+	}
+	if perms.GetAllowedActions() == "selected" {
+		allowed, _, err := ghs.client.Actions.GetActionsAllowed(ctx, org)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allowed actions: %w", err)
+		}
+		settings.GithubOwnedAllowed = allowed.GithubOwnedAllowed
+		settings.VerifiedAllowed = allowed.VerifiedAllowed
+		settings.PatternsAllowed = allowed.PatternsAllowed
+	}
+	wf, resp, err := ghs.client.Actions.GetDefaultWorkflowPermissionsInOrganization(ctx, org)
+	if err == nil {
+		settings.DefaultWorkflowPermissions = wf.DefaultWorkflowPermissions
+		settings.CanApprovePullRequestReviews = wf.CanApprovePullRequestReviews
+	} else if !actionsSettingNotApplicable(resp) {
+		return nil, fmt.Errorf("failed to get workflow permissions: %w", err)
+	}
+	approval, resp, err := ghs.client.Actions.GetOrganizationForkPRContributorApprovalPermissions(ctx, org)
+	if err == nil {
+		settings.ForkPRApprovalPolicy = github.Ptr(approval.ApprovalPolicy)
+	} else if !actionsSettingNotApplicable(resp) {
+		return nil, fmt.Errorf("failed to get fork pull request approval policy: %w", err)
+	}
+	return settings, nil
+}
+
+// actionsSettingNotApplicable reports whether a failed read means the setting
+// doesn't apply, e.g. the fork approval policy of a private repository.
+func actionsSettingNotApplicable(resp *github.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity)
+}
+
+func (ghs *GitHubService) updateRepoActionsSettings(ctx context.Context, owner, repo string, settings *GitHubActionsSettings, dryRun bool) *RepoSettingsResult {
+	result := &RepoSettingsResult{Repo: owner + "/" + repo}
+	if settings == nil {
+		result.Error = "no settings given"
+		return result
+	}
+	current, err := ghs.repoActionsSettings(ctx, owner, repo)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := validateActionsSettings(current, settings, false); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Changes = diffActionsSettings(current, settings)
+	if dryRun || len(result.Changes) == 0 {
+		return result
+	}
+
+	if settings.Enabled != nil || settings.AllowedActions != nil {
+		_, _, err = ghs.client.Repositories.UpdateActionsPermissions(ctx, owner, repo, github.ActionsPermissionsRepository{
+			Enabled:        orCurrent(settings.Enabled, current.Enabled),
+			AllowedActions: settings.AllowedActions,
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update permissions: %v", err)
+			return result
+		}
+	}
+	if allowed := actionsAllowedUpdate(current, settings); allowed != nil {
+		if _, _, err := ghs.client.Repositories.EditActionsAllowed(ctx, owner, repo, *allowed); err != nil {
+			result.Error = fmt.Sprintf("failed to update allowed actions: %v", err)
+			return result
+		}
+	}
+	if settings.DefaultWorkflowPermissions != nil || settings.CanApprovePullRequestReviews != nil {
+		_, _, err = ghs.client.Repositories.UpdateDefaultWorkflowPermissions(ctx, owner, repo, github.DefaultWorkflowPermissionRepository{
+			DefaultWorkflowPermissions:   settings.DefaultWorkflowPermissions,
+			CanApprovePullRequestReviews: settings.CanApprovePullRequestReviews,
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update workflow permissions: %v", err)
+			return result
+		}
+	}
+	if settings.ForkPRApprovalPolicy != nil {
+		_, err = ghs.client.Actions.UpdateForkPRContributorApprovalPermissions(ctx, owner, repo, github.ContributorApprovalPermissions{ApprovalPolicy: *settings.ForkPRApprovalPolicy})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update fork pull request approval: %v", err)
+			return result
+		}
+	}
+	result.Applied = true
+	return result
+}
+
+// validateActionsSettings rejects unknown values, fields of the other level
+// and selected-actions options for a policy that won't be "selected" once
+// applied on top of current.
+func validateActionsSettings(current, requested *GitHubActionsSettings, org bool) error {
+	if org && requested.Enabled != nil {
+		return fmt.Errorf("enabled applies to repositories; use enabled_repositories for an org")
+	}
+	if !org && requested.EnabledRepositories != nil {
+		return fmt.Errorf("enabled_repositories applies to orgs; use enabled for a repository")
+	}
+	check := func(name string, v *string, allowed ...string) error {
+		if v != nil && !slices.Contains(allowed, *v) {
+			return fmt.Errorf("invalid %s %q", name, *v)
+		}
+		return nil
+	}
+	if err := check("enabled_repositories", requested.EnabledRepositories, "all", "none", "selected"); err != nil {
+		return err
+	}
+	if err := check("allowed_actions", requested.AllowedActions, "all", "local_only", "selected"); err != nil {
+		return err
+	}
+	if err := check("default_workflow_permissions", requested.DefaultWorkflowPermissions, "read", "write"); err != nil {
+		return err
+	}
+	if err := check("fork_pr_approval_policy", requested.ForkPRApprovalPolicy, "first_time_contributors_new_to_github", "first_time_contributors", "all_external_contributors"); err != nil {
+		return err
+	}
+	if actionsAllowedUpdate(current, requested) != nil {
+		if allowedActions := orCurrent(requested.AllowedActions, current.AllowedActions); allowedActions == nil || *allowedActions != "selected" {
+			return fmt.Errorf("allowed actions options require allowed_actions \"selected\"")
+		}
+	}
+	return nil
+}
+
+// diffActionsSettings lists the requested settings that differ from current.
+func diffActionsSettings(current, requested *GitHubActionsSettings) []*RepoSettingChange {
+	var changes []*RepoSettingChange
+	diffBool := func(name string, cur, req *bool) {
+		if req != nil && (cur == nil || *cur != *req) {
+			changes = append(changes, &RepoSettingChange{Setting: name, OldValue: cur, NewValue: *req})
+		}
+	}
+	diffString := func(name string, cur, req *string) {
+		if req != nil && (cur == nil || *cur != *req) {
+			changes = append(changes, &RepoSettingChange{Setting: name, OldValue: cur, NewValue: *req})
+		}
+	}
+	diffBool("enabled", current.Enabled, requested.Enabled)
+	diffString("enabled_repositories", current.EnabledRepositories, requested.EnabledRepositories)
+	diffString("allowed_actions", current.AllowedActions, requested.AllowedActions)
+	diffBool("github_owned_allowed", current.GithubOwnedAllowed, requested.GithubOwnedAllowed)
+	diffBool("verified_allowed", current.VerifiedAllowed, requested.VerifiedAllowed)
+	if requested.PatternsAllowed != nil && !slices.Equal(current.PatternsAllowed, requested.PatternsAllowed) {
+		changes = append(changes, &RepoSettingChange{Setting: "patterns_allowed", OldValue: current.PatternsAllowed, NewValue: requested.PatternsAllowed})
+	}
+	diffString("default_workflow_permissions", current.DefaultWorkflowPermissions, requested.DefaultWorkflowPermissions)
+	diffBool("can_approve_pull_request_reviews", current.CanApprovePullRequestReviews, requested.CanApprovePullRequestReviews)
+	diffString("fork_pr_approval_policy", current.ForkPRApprovalPolicy, requested.ForkPRApprovalPolicy)
+	return changes
+}
+
+// actionsAllowedUpdate returns the selected-actions options to write, filled
+// in from current since the endpoint replaces them all, or nil when none were
+// requested.
+func actionsAllowedUpdate(current, requested *GitHubActionsSettings) *github.ActionsAllowed {
+	if requested.GithubOwnedAllowed == nil && requested.VerifiedAllowed == nil && requested.PatternsAllowed == nil {
+		return nil
+	}
+	patterns := requested.PatternsAllowed
+	if patterns == nil {
+		patterns = current.PatternsAllowed
+	}
+	return &github.ActionsAllowed{
+		GithubOwnedAllowed: orCurrent(requested.GithubOwnedAllowed, current.GithubOwnedAllowed),
+		VerifiedAllowed:    orCurrent(requested.VerifiedAllowed, current.VerifiedAllowed),
+		PatternsAllowed:    patterns,
+	}
+}
+
+// orCurrent returns requested, or current when requested is nil.
+func orCurrent[T any](requested, current *T) *T {
+	if requested != nil {
+		return requested
+	}
+	return current
+}
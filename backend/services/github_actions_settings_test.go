@@ -0,0 +1,98 @@
+package services
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func TestDiffActionsSettings(t *testing.T) {
+	current := &GitHubActionsSettings{
+		Enabled:                    github.Ptr(true),
+		AllowedActions:             github.Ptr("selected"),
+		VerifiedAllowed:            github.Ptr(false),
+		PatternsAllowed:            []string{"acme/*"},
+		DefaultWorkflowPermissions: github.Ptr("write"),
+	}
+	requested := &GitHubActionsSettings{
+		Enabled:                      github.Ptr(true),
+		VerifiedAllowed:              github.Ptr(true),
+		PatternsAllowed:              []string{"acme/*"},
+		DefaultWorkflowPermissions:   github.Ptr("read"),
+		CanApprovePullRequestReviews: github.Ptr(false),
+	}
+	var got []string
+	for _, c := range diffActionsSettings(current, requested) {
+		got = append(got, c.Setting)
+	}
+	want := []string{"verified_allowed", "default_workflow_permissions", "can_approve_pull_request_reviews"}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+func TestValidateActionsSettings(t *testing.T) {
+	selected := &GitHubActionsSettings{AllowedActions: github.Ptr("selected")}
+	all := &GitHubActionsSettings{AllowedActions: github.Ptr("all")}
+
+	tests := []struct {
+		name      string
+		current   *GitHubActionsSettings
+		requested *GitHubActionsSettings
+		org       bool
+		wantErr   bool
+	}{
+		{"repo enabled", all, &GitHubActionsSettings{Enabled: github.Ptr(false)}, false, false},
+		{"org enabled", all, &GitHubActionsSettings{Enabled: github.Ptr(false)}, true, true},
+		{"org enabled repositories", all, &GitHubActionsSettings{EnabledRepositories: github.Ptr("all")}, true, false},
+		{"repo enabled repositories", all, &GitHubActionsSettings{EnabledRepositories: github.Ptr("all")}, false, true},
+		{"invalid allowed actions", all, &GitHubActionsSettings{AllowedActions: github.Ptr("some")}, false, true},
+		{"invalid workflow permissions", all, &GitHubActionsSettings{DefaultWorkflowPermissions: github.Ptr("admin")}, false, true},
+		{"patterns with selected policy", selected, &GitHubActionsSettings{PatternsAllowed: []string{"acme/*"}}, false, false},
+		{"patterns without selected policy", all, &GitHubActionsSettings{PatternsAllowed: []string{"acme/*"}}, false, true},
+		{"patterns switching to selected", all, &GitHubActionsSettings{AllowedActions: github.Ptr("selected"), VerifiedAllowed: github.Ptr(true)}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateActionsSettings(tt.current, tt.requested, tt.org)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActionsAllowedUpdateKeepsCurrent(t *testing.T) {
+	current := &GitHubActionsSettings{
+		GithubOwnedAllowed: github.Ptr(true),
+		VerifiedAllowed:    github.Ptr(false),
+		PatternsAllowed:    []string{"acme/*"},
+	}
+	if got := actionsAllowedUpdate(current, &GitHubActionsSettings{}); got != nil {
+		t.Fatalf("got %v, want nil", got)
+	}
+	got := actionsAllowedUpdate(current, &GitHubActionsSettings{VerifiedAllowed: github.Ptr(true)})
+	if !got.GetVerifiedAllowed() || !got.GetGithubOwnedAllowed() || !slices.Equal(got.PatternsAllowed, []string{"acme/*"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestActionsSettingNotApplicable(t *testing.T) {
+	tests := map[int]bool{
+		http.StatusNotFound:            true,
+		http.StatusUnprocessableEntity: true,
+		http.StatusForbidden:           false,
+		http.StatusInternalServerError: false,
+	}
+	for code, want := range tests {
+		resp := &github.Response{Response: &http.Response{StatusCode: code}}
+		if got := actionsSettingNotApplicable(resp); got != want {
+			t.Errorf("status %d: got %v, want %v", code, got, want)
+		}
+	}
+	if actionsSettingNotApplicable(nil) {
+		t.Error("nil response treated as not applicable")
+	}
+}
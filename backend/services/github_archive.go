@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+const defaultRecentActivityDays = 30
+
+type ArchiveCheck struct {
+	Repo               string    `json:"repo"`
+	OpenPRs            int       `json:"open_prs"`
+	LastPush           time.Time `json:"last_push"`
+	RecentPush         bool      `json:"recent_push"`
+	ActiveWorkflows    []string  `json:"active_workflows"`
+	RecentWorkflowRuns int       `json:"recent_workflow_runs"`
+	Warnings           []string  `json:"warnings"`
+	Error              string    `json:"error"`
+}
+
+type ArchiveOptions struct {
+	DeprecationTopic   string `json:"deprecation_topic"`
+	ArchivedOnProperty string `json:"archived_on_property"` // Custom property set to today's date
+	RecentDays         int    `json:"recent_days"`
+	Force              bool   `json:"force"` // Archive even when the safety check raised warnings
+}
+
+type ArchiveResult struct {
+	Repo    string        `json:"repo"`
+	Success bool          `json:"success"`
+	Skipped bool          `json:"skipped"`
+	Check   *ArchiveCheck `json:"check"`
+	Error   string        `json:"error"`
+}
+
+// CheckArchiveSafety reports open pull requests, recent pushes and workflow
+// activity for each repository so they can be reviewed before archiving.
+func (ghs *GitHubService) CheckArchiveSafety(fullRepos []string, recentDays int) ([]*ArchiveCheck, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var checks []*ArchiveCheck
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			checks = append(checks, &ArchiveCheck{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		checks = append(checks, ghs.checkArchiveSafety(ctx, parts[0], parts[1], recentDays))
+	}
+	return checks, nil
+}
+
+func (ghs *GitHubService) ArchiveRepo(owner, repo string, options *ArchiveOptions) (*ArchiveResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if options == nil {
+		options = &ArchiveOptions{}
+	}
+	ctx := context.Background()
+	result := ghs.archiveRepo(ctx, owner, repo, options)
+	ghs.RefreshRepoList(owner)
+	return result, nil
+}
+
+func (ghs *GitHubService) BulkArchiveRepos(fullRepos []string, options *ArchiveOptions) ([]*ArchiveResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if options == nil {
+		options = &ArchiveOptions{}
+	}
+	ctx := context.Background()
+	var results []*ArchiveResult
+	owners := make(map[string]bool)
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &ArchiveResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		owners[parts[0]] = true
+		results = append(results, ghs.archiveRepo(ctx, parts[0], parts[1], options))
+	}
+	for owner := range owners {
+		ghs.RefreshRepoList(owner)
+	}
+	return results, nil
+}
+
+// UnarchiveRepo makes a repository writable again and, when given, removes
+// the deprecation topic that was added while archiving it.
+func (ghs *GitHubService) UnarchiveRepo(owner, repo, deprecationTopic string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	err := ghs.unarchiveRepo(ctx, owner, repo, deprecationTopic)
+	ghs.RefreshRepoList(owner)
+	return err
+}
+
+func (ghs *GitHubService) BulkUnarchiveRepos(fullRepos []string, deprecationTopic string) ([]*ArchiveResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*ArchiveResult
+	owners := make(map[string]bool)
+	for _, fullRepo := range fullRepos {
+		result := &ArchiveResult{Repo: fullRepo}
+		results = append(results, result)
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			result.Error = "invalid repository name"
+			continue
+		}
+		owners[parts[0]] = true
+		if err := ghs.unarchiveRepo(ctx, parts[0], parts[1], deprecationTopic); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Success = true
+	}
+	for owner := range owners {
+		ghs.RefreshRepoList(owner)
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) archiveRepo(ctx context.Context, owner, repo string, options *ArchiveOptions) *ArchiveResult {
+	result := &ArchiveResult{Repo: owner + "/" + repo}
+	check := ghs.checkArchiveSafety(ctx, owner, repo, options.RecentDays)
+	result.Check = check
+	if check.Error != "" {
+		result.Error = check.Error
+		return result
+	}
+	if len(check.Warnings) > 0 && !options.Force {
+		result.Skipped = true
+		return result
+	}
+
+	// Archived repositories are read-only, so topics and properties must be
+	// written first.
+	if options.DeprecationTopic != "" {
+		if _, err := ghs.UpdateRepoTopics(owner, repo, []string{options.DeprecationTopic}, "add"); err != nil {
+			result.Error = fmt.Sprintf("failed to add topic: %v", err)
+			return result
+		}
+	}
+	if options.ArchivedOnProperty != "" {
+		props := map[string]interface{}{options.ArchivedOnProperty: time.Now().Format("2006-01-02")}
+		if err := ghs.UpdateRepoCustomProperties(owner, repo, props); err != nil {
+			result.Error = fmt.Sprintf("failed to set %s: %v", options.ArchivedOnProperty, err)
+			return result
+		}
+	}
+
+	_, _, err := ghs.client.Repositories.Edit(ctx, owner, repo, &github.Repository{Archived: github.Ptr(true)})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+func (ghs *GitHubService) unarchiveRepo(ctx context.Context, owner, repo, deprecationTopic string) error {
+	_, _, err := ghs.client.Repositories.Edit(ctx, owner, repo, &github.Repository{Archived: github.Ptr(false)})
+	if err != nil {
+		return err
+	}
+	if deprecationTopic != "" {
+		if _, err := ghs.UpdateRepoTopics(owner, repo, []string{deprecationTopic}, "remove"); err != nil {
+			return fmt.Errorf("unarchived but failed to remove topic: %w", err)
+		}
+	}
+	return nil
+}
+
+func (ghs *GitHubService) checkArchiveSafety(ctx context.Context, owner, repo string, recentDays int) *ArchiveCheck {
+	if recentDays <= 0 {
+		recentDays = defaultRecentActivityDays
+	}
+	since := time.Now().AddDate(0, 0, -recentDays)
+	check := &ArchiveCheck{Repo: owner + "/" + repo}
+
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	if r.GetArchived() {
+		check.Warnings = append(check.Warnings, "repository is already archived")
+	}
+
+	check.LastPush = r.GetPushedAt().Time
+	if check.LastPush.After(since) {
+		check.RecentPush = true
+		check.Warnings = append(check.Warnings, fmt.Sprintf("pushed to within the last %d days", recentDays))
+	}
+
+	// A check that fails is a warning too, so it blocks archiving unless forced.
+	if openPRs, err := ghs.countOpenPRs(ctx, owner, repo); err == nil {
+		check.OpenPRs = openPRs
+		if openPRs > 0 {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("%d open pull requests", openPRs))
+		}
+	} else {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("could not check open pull requests: %v", err))
+	}
+
+	if workflows, _, err := ghs.client.Actions.ListWorkflows(ctx, owner, repo, &github.ListOptions{PerPage: 100}); err == nil {
+		for _, w := range workflows.Workflows {
+			if w.GetState() == "active" {
+				check.ActiveWorkflows = append(check.ActiveWorkflows, w.GetName())
+			}
+		}
+	} else {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("could not check workflows: %v", err))
+	}
+	if len(check.ActiveWorkflows) > 0 {
+		runs, _, err := ghs.client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, &github.ListWorkflowRunsOptions{
+			Created:     ">=" + since.Format("2006-01-02"),
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+		if err == nil {
+			check.RecentWorkflowRuns = runs.GetTotalCount()
+			if check.RecentWorkflowRuns > 0 {
+				check.Warnings = append(check.Warnings, fmt.Sprintf("%d workflow runs in the last %d days", check.RecentWorkflowRuns, recentDays))
+			}
+		} else {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("could not check recent workflow runs: %v", err))
+		}
+	}
+	return check
+}
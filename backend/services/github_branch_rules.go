@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// Source reported for rules that come from classic branch protection rather
+// than a ruleset.
+const ruleSourceClassic = "ClassicProtection"
+
+type EffectiveBranchRule struct {
+	Type        string `json:"type"`
+	SourceType  string `json:"source_type"` // "Repository", "Organization", "Enterprise" or "ClassicProtection"
+	Source      string `json:"source"`
+	RulesetID   int64  `json:"ruleset_id"`
+	RulesetName string `json:"ruleset_name"`
+	Parameters  any    `json:"parameters"`
+}
+
+type EffectiveBranchRules struct {
+	Branch    string                 `json:"branch"`
+	Protected bool                   `json:"protected"`
+	Rules     []*EffectiveBranchRule `json:"rules"`
+}
+
+// branchRule is a single entry of the rules-for-branch response. The typed
+// github.BranchRules groups rules by type and drops their order, so the raw
+// list is decoded instead.
+type branchRule struct {
+	Type              string          `json:"type"`
+	RulesetSourceType string          `json:"ruleset_source_type"`
+	RulesetSource     string          `json:"ruleset_source"`
+	RulesetID         int64           `json:"ruleset_id"`
+	Parameters        json.RawMessage `json:"parameters,omitempty"`
+}
+
+// GetEffectiveBranchRules combines the active ruleset rules for a branch with
+// its classic protection, annotating each rule with where it came from.
+func (ghs *GitHubService) GetEffectiveBranchRules(owner, repo, branch string) (*EffectiveBranchRules, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+
+	b, _, err := ghs.client.Repositories.GetBranch(ctx, owner, repo, branch, 1)
+	if err != nil {
+		return nil, err
+	}
+	effective := &EffectiveBranchRules{
+		Branch:    b.GetName(),
+		Protected: b.GetProtected(),
+	}
+
+	rules, err := ghs.listRulesForBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesetNames := make(map[int64]string)
+	if rulesets, err := ghs.fetchRulesets(ctx, owner, repo, true); err == nil {
+		for _, rs := range rulesets {
+			rulesetNames[rs.GetID()] = rs.Name
+		}
+	}
+
+	for _, r := range rules {
+		var params any
+		if len(r.Parameters) > 0 {
+			_ = json.Unmarshal(r.Parameters, &params)
+		}
+		effective.Rules = append(effective.Rules, &EffectiveBranchRule{
+			Type:        r.Type,
+			SourceType:  r.RulesetSourceType,
+			Source:      r.RulesetSource,
+			RulesetID:   r.RulesetID,
+			RulesetName: rulesetNames[r.RulesetID],
+			Parameters:  params,
+		})
+	}
+
+	if b.GetProtected() {
+		protection, _, err := ghs.client.Repositories.GetBranchProtection(ctx, owner, repo, branch)
+		if err == nil {
+			effective.Rules = append(effective.Rules, classicProtectionRules(owner+"/"+repo, protection)...)
+		} else if !errors.Is(err, github.ErrBranchNotProtected) {
+			return nil, err
+		}
+	}
+
+	return effective, nil
+}
+
+func (ghs *GitHubService) listRulesForBranch(ctx context.Context, owner, repo, branch string) ([]*branchRule, error) {
+	u := fmt.Sprintf("repos/%v/%v/rules/branches/%v?per_page=100", owner, repo, url.PathEscape(branch))
+	var all []*branchRule
+	for u != "" {
+		req, err := ghs.client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var rules []*branchRule
+		resp, err := ghs.client.Do(ctx, req, &rules)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rules...)
+		u = ""
+		if resp.NextPage != 0 {
+			u = fmt.Sprintf("repos/%v/%v/rules/branches/%v?per_page=100&page=%d", owner, repo, url.PathEscape(branch), resp.NextPage)
+		}
+	}
+	return all, nil
+}
+
+// classicProtectionRules expresses classic branch protection as ruleset rule
+// types where an equivalent exists, so both can be shown side by side.
+func classicProtectionRules(source string, p *github.Protection) []*EffectiveBranchRule {
+	var rules []*EffectiveBranchRule
+	add := func(ruleType string, params any) {
+		rules = append(rules, &EffectiveBranchRule{
+			Type:       ruleType,
+			SourceType: ruleSourceClassic,
+			Source:     source,
+			Parameters: params,
+		})
+	}
+
+	if rsc := p.GetRequiredStatusChecks(); rsc != nil {
+		add("required_status_checks", rsc)
+	}
+	if prr := p.GetRequiredPullRequestReviews(); prr != nil {
+		add("pull_request", prr)
+	}
+	if p.RequireLinearHistory != nil && p.RequireLinearHistory.Enabled {
+		add("required_linear_history", nil)
+	}
+	if p.AllowForcePushes == nil || !p.AllowForcePushes.Enabled {
+		add("non_fast_forward", nil)
+	}
+	if p.AllowDeletions == nil || !p.AllowDeletions.Enabled {
+		add("deletion", nil)
+	}
+	if p.GetRequiredSignatures().GetEnabled() {
+		add("required_signatures", nil)
+	}
+	if p.GetLockBranch().GetEnabled() {
+		add("update", p.LockBranch)
+	}
+	if p.RequiredConversationResolution != nil && p.RequiredConversationResolution.Enabled {
+		add("required_conversation_resolution", nil)
+	}
+	if p.GetBlockCreations().GetEnabled() {
+		add("creation", nil)
+	}
+	if r := p.GetRestrictions(); r != nil {
+		add("push_restrictions", r)
+	}
+	if p.EnforceAdmins != nil && p.EnforceAdmins.Enabled {
+		add("enforce_admins", nil)
+	}
+	return rules
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+const defaultStaleBranchDays = 90
+
+type GitHubBranchInfo struct {
+	Name           string    `json:"name"`
+	LastCommitSHA  string    `json:"last_commit_sha"`
+	LastCommitDate time.Time `json:"last_commit_date"`
+	Author         string    `json:"author"`
+	Ahead          int       `json:"ahead"`  // Commits on the branch missing from the default branch
+	Behind         int       `json:"behind"` // Commits on the default branch missing from the branch
+	Merged         bool      `json:"merged"` // Every commit is reachable from the default branch; squash merges aren't detected
+	Protected      bool      `json:"protected"`
+	Default        bool      `json:"default"`
+	Stale          bool      `json:"stale"`
+	Error          string    `json:"error"`
+}
+
+type BranchReport struct {
+	Repo          string              `json:"repo"`
+	DefaultBranch string              `json:"default_branch"`
+	StaleDays     int                 `json:"stale_days"`
+	Branches      []*GitHubBranchInfo `json:"branches"`
+	Error         string              `json:"error"`
+}
+
+type BranchDeleteResult struct {
+	Repo           string    `json:"repo"`
+	Branch         string    `json:"branch"`
+	LastCommitDate time.Time `json:"last_commit_date"`
+	Deleted        bool      `json:"deleted"`
+	Error          string    `json:"error"`
+}
+
+// GetBranchReport lists every branch of a repository with its last commit,
+// how far it is ahead of and behind the default branch, and whether it is
+// merged, protected or stale (no commit within staleDays).
+func (ghs *GitHubService) GetBranchReport(owner, repo string, staleDays int) (*BranchReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	report := ghs.branchReport(context.Background(), owner, repo, staleDays)
+	if report.Error != "" {
+		return nil, fmt.Errorf("%s", report.Error)
+	}
+	return report, nil
+}
+
+// GetRepoGroupBranchReport builds the branch report for every repository in
+// one of the org's repo groups.
+func (ghs *GitHubService) GetRepoGroupBranchReport(org, group string, staleDays int) ([]*BranchReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	repos, err := repoGroupRepos(org, group)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	var reports []*BranchReport
+	for _, fullRepo := range repos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			reports = append(reports, &BranchReport{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		reports = append(reports, ghs.branchReport(ctx, parts[0], parts[1], staleDays))
+	}
+	return reports, nil
+}
+
+// PreviewBranchCleanup lists the branches that DeleteBranches would accept:
+// stale, merged, unprotected branches other than the default branch.
+func (ghs *GitHubService) PreviewBranchCleanup(fullRepos []string, staleDays int) ([]*BranchDeleteResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*BranchDeleteResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &BranchDeleteResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		report := ghs.branchReport(ctx, parts[0], parts[1], staleDays)
+		if report.Error != "" {
+			results = append(results, &BranchDeleteResult{Repo: fullRepo, Error: report.Error})
+			continue
+		}
+		for _, b := range report.Branches {
+			if isCleanupCandidate(b) {
+				results = append(results, &BranchDeleteResult{Repo: report.Repo, Branch: b.Name, LastCommitDate: b.LastCommitDate})
+			}
+		}
+	}
+	return results, nil
+}
+
+// DeleteBranches deletes the given branches, keyed by full repository name,
+// typically as returned by PreviewBranchCleanup. Each branch is checked again
+// first so that protected, default or unmerged branches are never deleted.
+func (ghs *GitHubService) DeleteBranches(branches map[string][]string, staleDays int) ([]*BranchDeleteResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*BranchDeleteResult
+	for _, fullRepo := range slices.Sorted(maps.Keys(branches)) {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &BranchDeleteResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		report := ghs.branchReport(ctx, parts[0], parts[1], staleDays)
+		current := make(map[string]*GitHubBranchInfo)
+		for _, b := range report.Branches {
+			current[b.Name] = b
+		}
+		for _, name := range branches[fullRepo] {
+			result := &BranchDeleteResult{Repo: fullRepo, Branch: name}
+			results = append(results, result)
+			if report.Error != "" {
+				result.Error = report.Error
+				continue
+			}
+			b, ok := current[name]
+			if !ok {
+				result.Error = "branch not found"
+				continue
+			}
+			result.LastCommitDate = b.LastCommitDate
+			if !isCleanupCandidate(b) {
+				result.Error = "branch is no longer stale, merged and unprotected"
+				continue
+			}
+			if _, err := ghs.client.Git.DeleteRef(ctx, parts[0], parts[1], "heads/"+name); err != nil {
+				result.Error = err.Error()
+				continue
+			}
+			result.Deleted = true
+		}
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) branchReport(ctx context.Context, owner, repo string, staleDays int) *BranchReport {
+	if staleDays <= 0 {
+		staleDays = defaultStaleBranchDays
+	}
+	report := &BranchReport{Repo: owner + "/" + repo, StaleDays: staleDays}
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.DefaultBranch = r.GetDefaultBranch()
+	branches, err := ghs.listBranches(ctx, owner, repo)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	staleBefore := time.Now().AddDate(0, 0, -staleDays)
+	report.Branches = make([]*GitHubBranchInfo, len(branches))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for i, b := range branches {
+		info := &GitHubBranchInfo{
+			Name:          b.GetName(),
+			LastCommitSHA: b.GetCommit().GetSHA(),
+			Protected:     b.GetProtected(),
+			Default:       b.GetName() == report.DefaultBranch,
+		}
+		report.Branches[i] = info
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			commit, _, err := ghs.client.Repositories.GetCommit(ctx, owner, repo, info.LastCommitSHA, &github.ListOptions{PerPage: 1})
+			if err != nil {
+				info.Error = err.Error()
+				return
+			}
+			info.LastCommitDate = commit.GetCommit().GetCommitter().GetDate().Time
+			info.Author = commit.GetAuthor().GetLogin()
+			if info.Author == "" {
+				info.Author = commit.GetCommit().GetAuthor().GetName()
+			}
+			info.Stale = info.LastCommitDate.Before(staleBefore)
+			if info.Default {
+				return
+			}
+			cmp, _, err := ghs.client.Repositories.CompareCommits(ctx, owner, repo, report.DefaultBranch, info.LastCommitSHA, &github.ListOptions{PerPage: 1})
+			if err != nil {
+				info.Error = err.Error()
+				return
+			}
+			info.Ahead = cmp.GetAheadBy()
+			info.Behind = cmp.GetBehindBy()
+			info.Merged = info.Ahead == 0
+		}()
+	}
+	wg.Wait()
+	sort.Slice(report.Branches, func(i, j int) bool { return report.Branches[i].Name < report.Branches[j].Name })
+	return report
+}
+
+func (ghs *GitHubService) listBranches(ctx context.Context, owner, repo string) ([]*github.Branch, error) {
+	opt := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.Branch
+	for {
+		branches, resp, err := ghs.client.Repositories.ListBranches(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, branches...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func isCleanupCandidate(b *GitHubBranchInfo) bool {
+	return b.Error == "" && b.Stale && b.Merged && !b.Protected && !b.Default
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type GitHubCollaborator struct {
+	Login       string `json:"login"`
+	AvatarUrl   string `json:"avatar_url"`
+	Url         string `json:"url"`
+	Affiliation string `json:"affiliation"` // "direct" or "outside"
+	Permission  string `json:"permission"`
+}
+
+type GitHubRepoInvitation struct {
+	ID         int64     `json:"id"`
+	Invitee    string    `json:"invitee"`
+	Inviter    string    `json:"inviter"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+	Expired    bool      `json:"expired"`
+}
+
+type OutsideCollaboratorRepo struct {
+	Repo       string `json:"repo"`
+	Permission string `json:"permission"`
+}
+
+type OutsideCollaborator struct {
+	Login string                     `json:"login"`
+	Url   string                     `json:"url"`
+	Repos []*OutsideCollaboratorRepo `json:"repos"`
+}
+
+type OutsideCollaboratorsReport struct {
+	Org           string                 `json:"org"`
+	Collaborators []*OutsideCollaborator `json:"collaborators"`
+	Warnings      []string               `json:"warnings"`
+}
+
+// ListRepoCollaborators lists users with direct access to a repository,
+// marking those who are outside collaborators of the organization.
+func (ghs *GitHubService) ListRepoCollaborators(owner, repo string) ([]*GitHubCollaborator, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.listRepoCollaborators(context.Background(), owner, repo)
+}
+
+func (ghs *GitHubService) ListRepoInvitations(owner, repo string) ([]*GitHubRepoInvitation, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	invitations, err := ghs.listRepoInvitations(context.Background(), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	var all []*GitHubRepoInvitation
+	for _, inv := range invitations {
+		all = append(all, &GitHubRepoInvitation{
+			ID:         inv.GetID(),
+			Invitee:    inv.GetInvitee().GetLogin(),
+			Inviter:    inv.GetInviter().GetLogin(),
+			Permission: inv.GetPermissions(),
+			CreatedAt:  inv.GetCreatedAt().Time,
+			Expired:    inv.GetExpired(),
+		})
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) listRepoInvitations(ctx context.Context, owner, repo string) ([]*github.RepositoryInvitation, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.RepositoryInvitation
+	for {
+		invitations, resp, err := ghs.client.Repositories.ListInvitations(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, invitations...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// AddRepoCollaborator invites a user to a repository, or changes the
+// permission of an existing collaborator.
+func (ghs *GitHubService) AddRepoCollaborator(owner, repo, login, permission string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, _, err := ghs.client.Repositories.AddCollaborator(ctx, owner, repo, login, &github.RepositoryAddCollaboratorOptions{
+		Permission: permission,
+	})
+	return err
+}
+
+func (ghs *GitHubService) UpdateRepoCollaboratorPermission(owner, repo, login, permission string) error {
+	return ghs.AddRepoCollaborator(owner, repo, login, permission)
+}
+
+func (ghs *GitHubService) RemoveRepoCollaborator(owner, repo, login string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Repositories.RemoveCollaborator(ctx, owner, repo, login)
+	return err
+}
+
+func (ghs *GitHubService) UpdateRepoInvitation(owner, repo string, id int64, permission string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, _, err := ghs.client.Repositories.UpdateInvitation(ctx, owner, repo, id, permission)
+	return err
+}
+
+func (ghs *GitHubService) DeleteRepoInvitation(owner, repo string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Repositories.DeleteInvitation(ctx, owner, repo, id)
+	return err
+}
+
+// GetOutsideCollaboratorsReport lists every outside collaborator of an org
+// together with the repositories they can reach and their permission on each.
+// Repositories whose collaborators can't be listed are named in Warnings.
+func (ghs *GitHubService) GetOutsideCollaboratorsReport(org string) (*OutsideCollaboratorsReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	result := &OutsideCollaboratorsReport{Org: org}
+
+	reports := make(map[string]*OutsideCollaborator)
+	opt := &github.ListOutsideCollaboratorsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		users, resp, err := ghs.client.Organizations.ListOutsideCollaborators(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			reports[u.GetLogin()] = &OutsideCollaborator{Login: u.GetLogin(), Url: u.GetHTMLURL()}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	if len(reports) == 0 {
+		return result, nil
+	}
+
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for _, r := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			users, err := ghs.listCollaboratorUsers(ctx, org, repo.GetName(), "outside")
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", repo.GetFullName(), err))
+				return
+			}
+			for _, u := range users {
+				report, ok := reports[u.GetLogin()]
+				if !ok {
+					continue
+				}
+				report.Repos = append(report.Repos, &OutsideCollaboratorRepo{
+					Repo:       repo.GetFullName(),
+					Permission: collaboratorPermission(u),
+				})
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	for _, report := range reports {
+		sort.Slice(report.Repos, func(i, j int) bool { return report.Repos[i].Repo < report.Repos[j].Repo })
+		result.Collaborators = append(result.Collaborators, report)
+	}
+	sort.Slice(result.Collaborators, func(i, j int) bool { return result.Collaborators[i].Login < result.Collaborators[j].Login })
+	sort.Strings(result.Warnings)
+	return result, nil
+}
+
+func (ghs *GitHubService) listRepoCollaborators(ctx context.Context, owner, repo string) ([]*GitHubCollaborator, error) {
+	direct, err := ghs.listCollaboratorUsers(ctx, owner, repo, "direct")
+	if err != nil {
+		return nil, err
+	}
+	outside := make(map[string]bool)
+	// Listing outside collaborators fails for user-owned repositories.
+	if users, err := ghs.listCollaboratorUsers(ctx, owner, repo, "outside"); err == nil {
+		for _, u := range users {
+			outside[u.GetLogin()] = true
+		}
+	}
+
+	var collaborators []*GitHubCollaborator
+	for _, u := range direct {
+		affiliation := "direct"
+		if outside[u.GetLogin()] {
+			affiliation = "outside"
+		}
+		collaborators = append(collaborators, &GitHubCollaborator{
+			Login:       u.GetLogin(),
+			AvatarUrl:   u.GetAvatarURL(),
+			Url:         u.GetHTMLURL(),
+			Affiliation: affiliation,
+			Permission:  collaboratorPermission(u),
+		})
+	}
+	return collaborators, nil
+}
+
+func (ghs *GitHubService) listCollaboratorUsers(ctx context.Context, owner, repo, affiliation string) ([]*github.User, error) {
+	opt := &github.ListCollaboratorsOptions{
+		Affiliation: affiliation,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var all []*github.User
+	for {
+		users, resp, err := ghs.client.Repositories.ListCollaborators(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, users...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// collaboratorPermission returns the role name of a collaborator, falling
+// back to the highest permission flag when the role isn't reported.
+func collaboratorPermission(u *github.User) string {
+	if role := u.GetRoleName(); role != "" {
+		return role
+	}
+	for _, p := range []string{"admin", "maintain", "push", "triage", "pull"} {
+		if u.Permissions[p] {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,530 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type RepoCompareField struct {
+	Name     string `json:"name"`
+	A        any    `json:"a"`
+	B        any    `json:"b"`
+	Equal    bool   `json:"equal"`
+	Stricter string `json:"stricter"` // "a", "b", "same", or "" when strictness doesn't apply
+}
+
+type RepoCompareSection struct {
+	Name   string              `json:"name"`
+	OnlyIn string              `json:"only_in"` // "a", "b" or "" when present on both sides
+	Fields []*RepoCompareField `json:"fields"`
+}
+
+type RepoCompareTopics struct {
+	OnlyA []string `json:"only_a"`
+	OnlyB []string `json:"only_b"`
+	Both  []string `json:"both"`
+}
+
+type RepoCompareSummary struct {
+	Differences int `json:"differences"`
+	AStricter   int `json:"a_stricter"`
+	BStricter   int `json:"b_stricter"`
+}
+
+type RepoComparison struct {
+	A                string                `json:"a"`
+	B                string                `json:"b"`
+	Settings         []*RepoCompareField   `json:"settings"`
+	Topics           *RepoCompareTopics    `json:"topics"`
+	Teams            []*RepoCompareField   `json:"teams"`
+	CustomProperties []*RepoCompareField   `json:"custom_properties"`
+	Protection       []*RepoCompareSection `json:"protection"`
+	Rulesets         []*RepoCompareSection `json:"rulesets"`
+	Summary          *RepoCompareSummary   `json:"summary"`
+}
+
+var teamPermissionRank = map[string]int{"pull": 1, "read": 1, "triage": 2, "push": 3, "write": 3, "maintain": 4, "admin": 5}
+
+var visibilityRank = map[string]int{"public": 1, "internal": 2, "private": 3}
+
+var enforcementRank = map[string]int{"disabled": 0, "evaluate": 1, "active": 2}
+
+// CompareRepos returns a structured diff of the settings and governance of
+// two repositories, given as "owner/name".
+func (ghs *GitHubService) CompareRepos(a, b string) (*RepoComparison, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+
+	partsA := strings.Split(a, "/")
+	partsB := strings.Split(b, "/")
+	if len(partsA) != 2 || len(partsB) != 2 {
+		return nil, fmt.Errorf("repositories must be given as owner/name")
+	}
+
+	dataA, err := ghs.fetchCompareData(ctx, partsA[0], partsA[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", a, err)
+	}
+	dataB, err := ghs.fetchCompareData(ctx, partsB[0], partsB[1])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b, err)
+	}
+
+	cmp := &RepoComparison{
+		A:                a,
+		B:                b,
+		Settings:         compareSettings(dataA.repo, dataB.repo),
+		Topics:           compareTopics(dataA.repo.Topics, dataB.repo.Topics),
+		Teams:            compareTeams(dataA.teams, dataB.teams),
+		CustomProperties: compareCustomProperties(dataA.props, dataB.props),
+		Protection:       compareProtection(dataA.protection, dataB.protection),
+		Rulesets:         compareRulesets(dataA.rulesets, dataB.rulesets),
+	}
+	cmp.Summary = summarizeComparison(cmp)
+	return cmp, nil
+}
+
+// repoCompareData holds the sections of a repository that CompareRepos diffs.
+type repoCompareData struct {
+	repo       *github.Repository
+	teams      []*GitHubRepoTeam
+	props      []*github.CustomPropertyValue
+	protection []*GitHubBranchProtectionDetail
+	rulesets   []*github.RepositoryRuleset
+}
+
+// fetchCompareData fetches only what CompareRepos needs, rather than the full
+// GetRepoDetails.
+func (ghs *GitHubService) fetchCompareData(ctx context.Context, owner, name string) (*repoCompareData, error) {
+	repo, _, err := ghs.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	data := &repoCompareData{repo: repo}
+
+	teams, err := ghs.listRepoTeams(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("teams: %w", err)
+	}
+	for _, t := range teams {
+		data.teams = append(data.teams, &GitHubRepoTeam{Name: t.GetName(), Slug: t.GetSlug(), Permission: t.GetPermission()})
+	}
+
+	data.props, _, err = ghs.client.Repositories.GetAllCustomPropertyValues(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("custom properties: %w", err)
+	}
+	data.protection, err = ghs.fetchBranchProtections(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("branch protection: %w", err)
+	}
+	data.rulesets, err = ghs.fetchRulesets(ctx, owner, name, true)
+	if err != nil {
+		return nil, fmt.Errorf("rulesets: %w", err)
+	}
+	return data, nil
+}
+
+func compareSettings(a, b *github.Repository) []*RepoCompareField {
+	return []*RepoCompareField{
+		rankedField("visibility", a.GetVisibility(), b.GetVisibility(), visibilityRank),
+		valueField("default_branch", a.GetDefaultBranch(), b.GetDefaultBranch()),
+		valueField("archived", a.GetArchived(), b.GetArchived()),
+		valueField("allow_merge_commit", a.GetAllowMergeCommit(), b.GetAllowMergeCommit()),
+		valueField("allow_squash_merge", a.GetAllowSquashMerge(), b.GetAllowSquashMerge()),
+		valueField("allow_rebase_merge", a.GetAllowRebaseMerge(), b.GetAllowRebaseMerge()),
+		valueField("allow_auto_merge", a.GetAllowAutoMerge(), b.GetAllowAutoMerge()),
+		valueField("allow_update_branch", a.GetAllowUpdateBranch(), b.GetAllowUpdateBranch()),
+		valueField("delete_branch_on_merge", a.GetDeleteBranchOnMerge(), b.GetDeleteBranchOnMerge()),
+		valueField("squash_merge_commit_title", a.GetSquashMergeCommitTitle(), b.GetSquashMergeCommitTitle()),
+		valueField("has_issues", a.GetHasIssues(), b.GetHasIssues()),
+		valueField("has_wiki", a.GetHasWiki(), b.GetHasWiki()),
+		valueField("has_projects", a.GetHasProjects(), b.GetHasProjects()),
+		boolField("web_commit_signoff_required", a.GetWebCommitSignoffRequired(), b.GetWebCommitSignoffRequired(), true),
+	}
+}
+
+func compareTopics(a, b []string) *RepoCompareTopics {
+	inB := make(map[string]bool)
+	for _, t := range b {
+		inB[t] = true
+	}
+	inA := make(map[string]bool)
+	topics := &RepoCompareTopics{OnlyA: []string{}, OnlyB: []string{}, Both: []string{}}
+	for _, t := range a {
+		inA[t] = true
+		if inB[t] {
+			topics.Both = append(topics.Both, t)
+		} else {
+			topics.OnlyA = append(topics.OnlyA, t)
+		}
+	}
+	for _, t := range b {
+		if !inA[t] {
+			topics.OnlyB = append(topics.OnlyB, t)
+		}
+	}
+	sort.Strings(topics.OnlyA)
+	sort.Strings(topics.OnlyB)
+	sort.Strings(topics.Both)
+	return topics
+}
+
+// compareTeams diffs direct team grants by slug. Less access is treated as
+// stricter. Inherited grants are ignored since they follow from the team
+// hierarchy rather than the repository.
+func compareTeams(a, b []*GitHubRepoTeam) []*RepoCompareField {
+	permsA := make(map[string]string)
+	for _, t := range a {
+		if !t.Inherited {
+			permsA[t.Slug] = t.Permission
+		}
+	}
+	permsB := make(map[string]string)
+	for _, t := range b {
+		if !t.Inherited {
+			permsB[t.Slug] = t.Permission
+		}
+	}
+	var fields []*RepoCompareField
+	for _, slug := range unionKeys(permsA, permsB) {
+		field := &RepoCompareField{Name: slug, Equal: permsA[slug] == permsB[slug]}
+		if p, ok := permsA[slug]; ok {
+			field.A = p
+		}
+		if p, ok := permsB[slug]; ok {
+			field.B = p
+		}
+		field.Stricter = stricterByRank(-teamPermissionRank[permsA[slug]], -teamPermissionRank[permsB[slug]])
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func compareCustomProperties(a, b []*github.CustomPropertyValue) []*RepoCompareField {
+	valuesA := make(map[string]any)
+	for _, p := range a {
+		valuesA[p.PropertyName] = p.Value
+	}
+	valuesB := make(map[string]any)
+	for _, p := range b {
+		valuesB[p.PropertyName] = p.Value
+	}
+	var fields []*RepoCompareField
+	for _, name := range unionKeys(valuesA, valuesB) {
+		fields = append(fields, &RepoCompareField{
+			Name:  name,
+			A:     valuesA[name],
+			B:     valuesB[name],
+			Equal: customPropertyValuesEqual(valuesA[name], valuesB[name]),
+		})
+	}
+	return fields
+}
+
+func compareProtection(a, b []*GitHubBranchProtectionDetail) []*RepoCompareSection {
+	byBranchA := make(map[string]*github.Protection)
+	for _, p := range a {
+		byBranchA[p.BranchName] = p.Protection
+	}
+	byBranchB := make(map[string]*github.Protection)
+	for _, p := range b {
+		byBranchB[p.BranchName] = p.Protection
+	}
+
+	var sections []*RepoCompareSection
+	for _, branch := range unionKeys(byBranchA, byBranchB) {
+		pa, pb := byBranchA[branch], byBranchB[branch]
+		section := &RepoCompareSection{Name: branch, OnlyIn: onlyIn(pa != nil, pb != nil)}
+		fa, fb := classicFlags(pa), classicFlags(pb)
+		section.Fields = []*RepoCompareField{
+			boolField("protected", pa != nil, pb != nil, true),
+			boolField("require_pull_request", fa.pullRequest, fb.pullRequest, true),
+			intField("required_approving_review_count", fa.approvals, fb.approvals),
+			boolField("dismiss_stale_reviews", fa.dismissStale, fb.dismissStale, true),
+			boolField("require_code_owner_reviews", fa.codeOwners, fb.codeOwners, true),
+			boolField("require_last_push_approval", fa.lastPush, fb.lastPush, true),
+			setField("required_status_checks", fa.statusChecks, fb.statusChecks),
+			boolField("strict_status_checks", fa.strictChecks, fb.strictChecks, true),
+			boolField("enforce_admins", fa.enforceAdmins, fb.enforceAdmins, true),
+			boolField("required_linear_history", fa.linearHistory, fb.linearHistory, true),
+			boolField("allow_force_pushes", fa.forcePushes, fb.forcePushes, false),
+			boolField("allow_deletions", fa.deletions, fb.deletions, false),
+			boolField("required_conversation_resolution", fa.conversations, fb.conversations, true),
+			boolField("required_signatures", fa.signatures, fb.signatures, true),
+			boolField("lock_branch", fa.locked, fb.locked, true),
+			boolField("push_restrictions", fa.restricted, fb.restricted, true),
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// compareRulesets matches rulesets by name and compares enforcement, bypass
+// actors and each rule type. Having a rule is stricter than not having it.
+func compareRulesets(a, b []*github.RepositoryRuleset) []*RepoCompareSection {
+	byNameA := make(map[string]*github.RepositoryRuleset)
+	for _, rs := range a {
+		byNameA[rs.Name] = rs
+	}
+	byNameB := make(map[string]*github.RepositoryRuleset)
+	for _, rs := range b {
+		byNameB[rs.Name] = rs
+	}
+
+	var sections []*RepoCompareSection
+	for _, name := range unionKeys(byNameA, byNameB) {
+		ra, rb := byNameA[name], byNameB[name]
+		section := &RepoCompareSection{Name: name, OnlyIn: onlyIn(ra != nil, rb != nil)}
+
+		var enfA, enfB string
+		var bypassA, bypassB int
+		if ra != nil {
+			enfA = string(ra.Enforcement)
+			bypassA = len(ra.BypassActors)
+		}
+		if rb != nil {
+			enfB = string(rb.Enforcement)
+			bypassB = len(rb.BypassActors)
+		}
+		section.Fields = append(section.Fields,
+			rankedField("enforcement", enfA, enfB, enforcementRank),
+			&RepoCompareField{Name: "bypass_actors", A: bypassA, B: bypassB, Equal: bypassA == bypassB, Stricter: stricterByRank(-bypassA, -bypassB)},
+		)
+
+		rulesA, rulesB := rulesetRulesByType(ra), rulesetRulesByType(rb)
+		for _, ruleType := range unionKeys(rulesA, rulesB) {
+			pa, okA := rulesA[ruleType]
+			pb, okB := rulesB[ruleType]
+			field := &RepoCompareField{Name: ruleType, A: pa, B: pb, Equal: okA == okB && governanceEqual(pa, pb)}
+			switch {
+			case okA && !okB:
+				field.Stricter = "a"
+			case okB && !okA:
+				field.Stricter = "b"
+			case ruleType == string(github.RulesetRuleTypePullRequest):
+				field.Stricter = stricterByRank(pullRequestApprovals(pa), pullRequestApprovals(pb))
+			case field.Equal:
+				field.Stricter = "same"
+			}
+			section.Fields = append(section.Fields, field)
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+func summarizeComparison(cmp *RepoComparison) *RepoCompareSummary {
+	summary := &RepoCompareSummary{}
+	count := func(fields []*RepoCompareField) {
+		for _, f := range fields {
+			if !f.Equal {
+				summary.Differences++
+			}
+			switch f.Stricter {
+			case "a":
+				summary.AStricter++
+			case "b":
+				summary.BStricter++
+			}
+		}
+	}
+	count(cmp.Settings)
+	count(cmp.Teams)
+	count(cmp.CustomProperties)
+	for _, s := range cmp.Protection {
+		count(s.Fields)
+	}
+	for _, s := range cmp.Rulesets {
+		count(s.Fields)
+	}
+	summary.Differences += len(cmp.Topics.OnlyA) + len(cmp.Topics.OnlyB)
+	return summary
+}
+
+type classicRuleFlags struct {
+	pullRequest   bool
+	approvals     int
+	dismissStale  bool
+	codeOwners    bool
+	lastPush      bool
+	statusChecks  []string
+	strictChecks  bool
+	enforceAdmins bool
+	linearHistory bool
+	forcePushes   bool
+	deletions     bool
+	conversations bool
+	signatures    bool
+	locked        bool
+	restricted    bool
+}
+
+// classicFlags flattens a classic protection into comparable values. A nil
+// protection yields the flags of an unprotected branch.
+func classicFlags(p *github.Protection) classicRuleFlags {
+	if p == nil {
+		return classicRuleFlags{forcePushes: true, deletions: true}
+	}
+	flags := classicRuleFlags{
+		enforceAdmins: p.EnforceAdmins != nil && p.EnforceAdmins.Enabled,
+		linearHistory: p.RequireLinearHistory != nil && p.RequireLinearHistory.Enabled,
+		forcePushes:   p.AllowForcePushes != nil && p.AllowForcePushes.Enabled,
+		deletions:     p.AllowDeletions != nil && p.AllowDeletions.Enabled,
+		conversations: p.RequiredConversationResolution != nil && p.RequiredConversationResolution.Enabled,
+		signatures:    p.GetRequiredSignatures().GetEnabled(),
+		locked:        p.GetLockBranch().GetEnabled(),
+		restricted:    p.Restrictions != nil,
+	}
+	if prr := p.GetRequiredPullRequestReviews(); prr != nil {
+		flags.pullRequest = true
+		flags.approvals = prr.RequiredApprovingReviewCount
+		flags.dismissStale = prr.DismissStaleReviews
+		flags.codeOwners = prr.RequireCodeOwnerReviews
+		flags.lastPush = prr.RequireLastPushApproval
+	}
+	if rsc := p.GetRequiredStatusChecks(); rsc != nil {
+		flags.strictChecks = rsc.Strict
+		if rsc.Checks != nil {
+			for _, c := range *rsc.Checks {
+				flags.statusChecks = append(flags.statusChecks, c.Context)
+			}
+		} else if rsc.Contexts != nil {
+			flags.statusChecks = append(flags.statusChecks, *rsc.Contexts...)
+		}
+	}
+	return flags
+}
+
+// rulesetRulesByType returns each rule's parameters keyed by rule type.
+func rulesetRulesByType(rs *github.RepositoryRuleset) map[string]any {
+	rules := make(map[string]any)
+	if rs == nil || rs.Rules == nil {
+		return rules
+	}
+	data, err := json.Marshal(rs.Rules)
+	if err != nil {
+		return rules
+	}
+	var raw []struct {
+		Type       string `json:"type"`
+		Parameters any    `json:"parameters"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return rules
+	}
+	for _, r := range raw {
+		rules[r.Type] = r.Parameters
+	}
+	return rules
+}
+
+func pullRequestApprovals(params any) int {
+	if m, ok := params.(map[string]any); ok {
+		if n, ok := m["required_approving_review_count"].(float64); ok {
+			return int(n)
+		}
+	}
+	return 0
+}
+
+func valueField(name string, a, b any) *RepoCompareField {
+	return &RepoCompareField{Name: name, A: a, B: b, Equal: governanceEqual(a, b)}
+}
+
+// boolField compares a flag where enabled is the stricter value, or the
+// looser one when trueIsStricter is false.
+func boolField(name string, a, b bool, trueIsStricter bool) *RepoCompareField {
+	field := &RepoCompareField{Name: name, A: a, B: b, Equal: a == b, Stricter: "same"}
+	if a != b {
+		if a == trueIsStricter {
+			field.Stricter = "a"
+		} else {
+			field.Stricter = "b"
+		}
+	}
+	return field
+}
+
+func intField(name string, a, b int) *RepoCompareField {
+	return &RepoCompareField{Name: name, A: a, B: b, Equal: a == b, Stricter: stricterByRank(a, b)}
+}
+
+func rankedField(name, a, b string, rank map[string]int) *RepoCompareField {
+	return &RepoCompareField{Name: name, A: a, B: b, Equal: a == b, Stricter: stricterByRank(rank[a], rank[b])}
+}
+
+// setField compares two lists of names. A strict superset is stricter.
+func setField(name string, a, b []string) *RepoCompareField {
+	setA := make(map[string]bool)
+	for _, v := range a {
+		setA[v] = true
+	}
+	setB := make(map[string]bool)
+	for _, v := range b {
+		setB[v] = true
+	}
+	aHasAllB, bHasAllA := true, true
+	for v := range setB {
+		if !setA[v] {
+			aHasAllB = false
+		}
+	}
+	for v := range setA {
+		if !setB[v] {
+			bHasAllA = false
+		}
+	}
+	field := &RepoCompareField{Name: name, A: a, B: b, Equal: aHasAllB && bHasAllA}
+	switch {
+	case field.Equal:
+		field.Stricter = "same"
+	case aHasAllB:
+		field.Stricter = "a"
+	case bHasAllA:
+		field.Stricter = "b"
+	}
+	return field
+}
+
+func stricterByRank(a, b int) string {
+	switch {
+	case a > b:
+		return "a"
+	case b > a:
+		return "b"
+	default:
+		return "same"
+	}
+}
+
+func onlyIn(inA, inB bool) string {
+	switch {
+	case inA && !inB:
+		return "a"
+	case inB && !inA:
+		return "b"
+	default:
+		return ""
+	}
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return slices.Sorted(maps.Keys(keys))
+}
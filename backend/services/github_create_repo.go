@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type CreateRepositorySpec struct {
+	Name               string        `json:"name"`
+	Description        string        `json:"description"`
+	Visibility         string        `json:"visibility"` // "public", "private" or "internal"
+	DefaultBranch      string        `json:"default_branch"`
+	TemplateRepo       string        `json:"template_repo"` // "owner/name", empty for a new repository
+	IncludeAllBranches bool          `json:"include_all_branches"`
+	AutoInit           bool          `json:"auto_init"`
+	BaselineName       string        `json:"baseline_name"` // Looked up in Config.Baselines for the org
+	Baseline           *RepoBaseline `json:"baseline"`      // Used when BaselineName is empty
+	RollbackOnFailure  bool          `json:"rollback_on_failure"`
+}
+
+type CreateRepositoryStep struct {
+	Step    string `json:"step"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped"`
+	Error   string `json:"error"`
+}
+
+type CreateRepositoryResult struct {
+	Repo       *GitHubRepo             `json:"repo"`
+	Steps      []*CreateRepositoryStep `json:"steps"`
+	Success    bool                    `json:"success"`
+	RolledBack bool                    `json:"rolled_back"`
+}
+
+// CreateRepository creates a repository, empty or from a template, then
+// applies a baseline of teams, topics, custom properties and a ruleset
+// template. Each step is reported; if one fails and RollbackOnFailure is set
+// the new repository is deleted again.
+func (ghs *GitHubService) CreateRepository(org string, spec *CreateRepositorySpec) (*CreateRepositoryResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if spec == nil || spec.Name == "" {
+		return nil, fmt.Errorf("repository name is required")
+	}
+	ctx := context.Background()
+
+	baseline := spec.Baseline
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if spec.BaselineName != "" {
+		baseline = cfg.Baselines[org][spec.BaselineName]
+		if baseline == nil {
+			return nil, fmt.Errorf("baseline %q not found for %s", spec.BaselineName, org)
+		}
+	}
+
+	result := &CreateRepositoryResult{}
+	step := func(name string, err error) bool {
+		s := &CreateRepositoryStep{Step: name, Success: err == nil}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		result.Steps = append(result.Steps, s)
+		return err == nil
+	}
+	skip := func(name string) {
+		result.Steps = append(result.Steps, &CreateRepositoryStep{Step: name, Skipped: true})
+	}
+
+	repo, err := ghs.createRepository(ctx, org, spec)
+	if !step("create", err) {
+		return result, nil
+	}
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+	ok := true
+	// Templates can only create public or private repositories.
+	if spec.TemplateRepo != "" && spec.Visibility == "internal" {
+		edited, _, err := ghs.client.Repositories.Edit(ctx, owner, name, &github.Repository{Visibility: github.Ptr("internal")})
+		if ok = step("visibility", err); ok {
+			repo = edited
+		}
+	} else {
+		skip("visibility")
+	}
+
+	if ok && spec.DefaultBranch != "" && spec.DefaultBranch != repo.GetDefaultBranch() {
+		ok = step("default_branch", ghs.setDefaultBranchName(ctx, owner, name, repo, spec.DefaultBranch))
+	} else if ok {
+		skip("default_branch")
+	}
+
+	if ok && baseline != nil && baseline.TeamGroup != "" {
+		members, found := cfg.TeamGroups[org][baseline.TeamGroup]
+		if !found {
+			ok = step("teams", fmt.Errorf("team group %q not found", baseline.TeamGroup))
+		} else {
+			var teamErr error
+			for _, m := range members {
+				if err := ghs.UpdateRepoTeam(owner, name, org, m.Slug, m.Permission, false); err != nil {
+					teamErr = fmt.Errorf("%s: %w", m.Slug, err)
+					break
+				}
+			}
+			ok = step("teams", teamErr)
+		}
+	} else if ok {
+		skip("teams")
+	}
+
+	if ok && baseline != nil && len(baseline.Topics) > 0 {
+		_, err := ghs.UpdateRepoTopics(owner, name, baseline.Topics, "replace")
+		ok = step("topics", err)
+	} else if ok {
+		skip("topics")
+	}
+
+	if ok && baseline != nil && len(baseline.CustomProperties) > 0 {
+		ok = step("custom_properties", ghs.UpdateRepoCustomProperties(org, name, baseline.CustomProperties))
+	} else if ok {
+		skip("custom_properties")
+	}
+
+	if ok && baseline != nil && baseline.RulesetTemplate != "" {
+		ok = step("ruleset", ghs.applyRulesetTemplateToRepo(owner, name, baseline.RulesetTemplate, baseline.RulesetParams))
+	} else if ok {
+		skip("ruleset")
+	}
+
+	if !ok && spec.RollbackOnFailure {
+		_, err := ghs.client.Repositories.Delete(ctx, owner, name)
+		result.RolledBack = step("rollback", err)
+		if result.RolledBack {
+			return result, nil
+		}
+	}
+
+	result.Success = ok
+	result.Repo = &GitHubRepo{
+		Name:          repo.GetName(),
+		FullName:      repo.GetFullName(),
+		Url:           repo.GetHTMLURL(),
+		Topics:        repo.Topics,
+		Public:        !repo.GetPrivate(),
+		Visibility:    repo.GetVisibility(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		CanManage:     true,
+	}
+	if spec.DefaultBranch != "" && ok {
+		result.Repo.DefaultBranch = spec.DefaultBranch
+	}
+	if baseline != nil && len(baseline.Topics) > 0 && ok {
+		result.Repo.Topics = baseline.Topics
+	}
+	ghs.RefreshRepoList(org)
+	return result, nil
+}
+
+func (ghs *GitHubService) createRepository(ctx context.Context, org string, spec *CreateRepositorySpec) (*github.Repository, error) {
+	private := spec.Visibility != "public"
+
+	if spec.TemplateRepo != "" {
+		parts := strings.Split(spec.TemplateRepo, "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid template repository %q", spec.TemplateRepo)
+		}
+		repo, _, err := ghs.client.Repositories.CreateFromTemplate(ctx, parts[0], parts[1], &github.TemplateRepoRequest{
+			Name:               github.Ptr(spec.Name),
+			Owner:              github.Ptr(org),
+			Description:        github.Ptr(spec.Description),
+			IncludeAllBranches: github.Ptr(spec.IncludeAllBranches),
+			Private:            github.Ptr(private),
+		})
+		return repo, err
+	}
+
+	user, _, err := ghs.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	owner := org
+	if user.GetLogin() == org {
+		owner = ""
+	}
+	newRepo := &github.Repository{
+		Name:        github.Ptr(spec.Name),
+		Description: github.Ptr(spec.Description),
+		Private:     github.Ptr(private),
+		AutoInit:    github.Ptr(spec.AutoInit || spec.DefaultBranch != ""),
+	}
+	if spec.Visibility != "" && owner != "" {
+		newRepo.Visibility = github.Ptr(spec.Visibility)
+	}
+	repo, _, err := ghs.client.Repositories.Create(ctx, owner, newRepo)
+	return repo, err
+}
+
+// setDefaultBranchName renames the initial branch of a freshly created
+// repository so it matches the requested default branch.
+func (ghs *GitHubService) setDefaultBranchName(ctx context.Context, owner, name string, repo *github.Repository, branch string) error {
+	if repo.GetDefaultBranch() == "" {
+		return fmt.Errorf("repository has no branches to rename")
+	}
+	_, _, err := ghs.client.Repositories.RenameBranch(ctx, owner, name, repo.GetDefaultBranch(), branch)
+	return err
+}
+
+func (ghs *GitHubService) applyRulesetTemplateToRepo(owner, repo, templateName string, params *RulesetTemplateParams) error {
+	ruleset, err := templateRuleset(templateName, params)
+	if err != nil {
+		return err
+	}
+	result := ghs.applyRuleset(owner, repo, ruleset, params != nil && params.UpdateExisting)
+	if result.Error != "" {
+		return fmt.Errorf("%s", result.Error)
+	}
+	if result.Action == "skipped" {
+		return fmt.Errorf("%s", result.Reason)
+	}
+	return nil
+}
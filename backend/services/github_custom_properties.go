@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// GitHub accepts at most 30 repositories per custom property values update.
+const customPropertyBatchSize = 30
+
+type CustomPropertyImportChange struct {
+	PropertyName string `json:"property_name"`
+	OldValue     any    `json:"old_value"`
+	NewValue     any    `json:"new_value"`
+}
+
+type CustomPropertyImportRow struct {
+	Line    int                           `json:"line"`
+	Repo    string                        `json:"repo"`
+	Values  []*github.CustomPropertyValue `json:"values"`
+	Changes []*CustomPropertyImportChange `json:"changes"`
+	Errors  []string                      `json:"errors"`
+}
+
+type CustomPropertyImportPreview struct {
+	Org     string                     `json:"org"`
+	Columns map[string]string          `json:"columns"` // CSV header -> property name
+	Rows    []*CustomPropertyImportRow `json:"rows"`
+	Errors  []string                   `json:"errors"`
+}
+
+type CustomPropertyImportResult struct {
+	Repo    string `json:"repo"`
+	Skipped bool   `json:"skipped"`
+	Error   string `json:"error"`
+}
+
+// PreviewCustomPropertyImport parses CSV content where one column identifies
+// the repository and the remaining mapped columns hold property values. When
+// columnMap is empty, headers that match a property name are used as-is.
+func (ghs *GitHubService) PreviewCustomPropertyImport(org, csvContent, repoColumn string, columnMap map[string]string) (*CustomPropertyImportPreview, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+
+	reader := csv.NewReader(strings.NewReader(csvContent))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV must contain a header row and at least one data row")
+	}
+
+	defs, _, err := ghs.client.Organizations.GetAllCustomProperties(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	defsByName := make(map[string]*github.CustomProperty)
+	for _, d := range defs {
+		defsByName[d.GetPropertyName()] = d
+	}
+
+	current, err := ghs.listAllCustomPropertyValues(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &CustomPropertyImportPreview{
+		Org:     org,
+		Columns: make(map[string]string),
+	}
+
+	header := records[0]
+	repoIdx := -1
+	propIdx := make(map[int]string)
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if h == repoColumn {
+			repoIdx = i
+			continue
+		}
+		propName := h
+		if len(columnMap) > 0 {
+			mapped, ok := columnMap[h]
+			if !ok || mapped == "" {
+				continue
+			}
+			propName = mapped
+		}
+		if _, ok := defsByName[propName]; !ok {
+			if len(columnMap) > 0 {
+				preview.Errors = append(preview.Errors, fmt.Sprintf("column %q maps to unknown property %q", h, propName))
+			}
+			continue
+		}
+		propIdx[i] = propName
+		preview.Columns[h] = propName
+	}
+	if repoIdx == -1 {
+		return nil, fmt.Errorf("repository column %q not found in CSV header", repoColumn)
+	}
+	if len(propIdx) == 0 {
+		return nil, fmt.Errorf("no CSV columns map to a custom property")
+	}
+
+	seen := make(map[string]int)
+	for n, record := range records[1:] {
+		line := n + 2
+		row := &CustomPropertyImportRow{Line: line}
+		preview.Rows = append(preview.Rows, row)
+
+		if repoIdx >= len(record) || strings.TrimSpace(record[repoIdx]) == "" {
+			row.Errors = append(row.Errors, "missing repository name")
+			continue
+		}
+		repoName := strings.TrimSpace(record[repoIdx])
+		if parts := strings.Split(repoName, "/"); len(parts) == 2 {
+			if !strings.EqualFold(parts[0], org) {
+				row.Repo = repoName
+				row.Errors = append(row.Errors, fmt.Sprintf("repository does not belong to %s", org))
+				continue
+			}
+			repoName = parts[1]
+		}
+		row.Repo = repoName
+
+		if prev, ok := seen[strings.ToLower(repoName)]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("duplicate of line %d", prev))
+			continue
+		}
+		seen[strings.ToLower(repoName)] = line
+
+		existing, ok := current[strings.ToLower(repoName)]
+		if !ok {
+			row.Errors = append(row.Errors, "repository not found in organization")
+			continue
+		}
+
+		for i, propName := range propIdx {
+			raw := ""
+			if i < len(record) {
+				raw = strings.TrimSpace(record[i])
+			}
+			value, err := validateCustomPropertyValue(defsByName[propName], raw)
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", propName, err))
+				continue
+			}
+			row.Values = append(row.Values, &github.CustomPropertyValue{
+				PropertyName: propName,
+				Value:        value,
+			})
+			if !customPropertyValuesEqual(existing[propName], value) {
+				row.Changes = append(row.Changes, &CustomPropertyImportChange{
+					PropertyName: propName,
+					OldValue:     existing[propName],
+					NewValue:     value,
+				})
+			}
+		}
+		sort.Slice(row.Values, func(a, b int) bool { return row.Values[a].PropertyName < row.Values[b].PropertyName })
+		sort.Slice(row.Changes, func(a, b int) bool { return row.Changes[a].PropertyName < row.Changes[b].PropertyName })
+	}
+
+	return preview, nil
+}
+
+// ApplyCustomPropertyImport writes the changed values from a preview. Rows
+// sharing the same set of changes are batched into a single API call.
+func (ghs *GitHubService) ApplyCustomPropertyImport(org string, rows []*CustomPropertyImportRow) ([]*CustomPropertyImportResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+
+	var results []*CustomPropertyImportResult
+	batches := make(map[string][]string)
+	batchProps := make(map[string][]*github.CustomPropertyValue)
+	var batchOrder []string
+
+	for _, row := range rows {
+		if len(row.Errors) > 0 || len(row.Changes) == 0 {
+			results = append(results, &CustomPropertyImportResult{Repo: row.Repo, Skipped: true})
+			continue
+		}
+		var props []*github.CustomPropertyValue
+		for _, c := range row.Changes {
+			props = append(props, &github.CustomPropertyValue{PropertyName: c.PropertyName, Value: c.NewValue})
+		}
+		sort.Slice(props, func(a, b int) bool { return props[a].PropertyName < props[b].PropertyName })
+		key, err := json.Marshal(props)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := batches[string(key)]; !ok {
+			batchOrder = append(batchOrder, string(key))
+			batchProps[string(key)] = props
+		}
+		batches[string(key)] = append(batches[string(key)], row.Repo)
+	}
+
+	for _, key := range batchOrder {
+		repos := batches[key]
+		for start := 0; start < len(repos); start += customPropertyBatchSize {
+			end := min(start+customPropertyBatchSize, len(repos))
+			chunk := repos[start:end]
+			_, err := ghs.client.Organizations.CreateOrUpdateRepoCustomPropertyValues(ctx, org, chunk, batchProps[key])
+			for _, repo := range chunk {
+				result := &CustomPropertyImportResult{Repo: repo}
+				if err != nil {
+					result.Error = err.Error()
+				}
+				results = append(results, result)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// listAllCustomPropertyValues returns every repository's property values keyed
+// by lower-cased repository name, then by property name.
+func (ghs *GitHubService) listAllCustomPropertyValues(ctx context.Context, org string) (map[string]map[string]any, error) {
+	values := make(map[string]map[string]any)
+	opt := &github.ListCustomPropertyValuesOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		repos, resp, err := ghs.client.Organizations.ListCustomPropertyValues(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			props := make(map[string]any)
+			for _, p := range r.Properties {
+				props[p.PropertyName] = p.Value
+			}
+			values[strings.ToLower(r.RepositoryName)] = props
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return values, nil
+}
+
+// validateCustomPropertyValue converts a raw CSV cell into the value shape
+// GitHub expects for the property type. An empty cell unsets the property.
+func validateCustomPropertyValue(def *github.CustomProperty, raw string) (any, error) {
+	if raw == "" {
+		if def.GetRequired() {
+			return nil, fmt.Errorf("value is required")
+		}
+		return nil, nil
+	}
+
+	isAllowed := func(v string) bool {
+		if len(def.AllowedValues) == 0 {
+			return true
+		}
+		for _, a := range def.AllowedValues {
+			if a == v {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch def.ValueType {
+	case "true_false":
+		switch strings.ToLower(raw) {
+		case "true":
+			return "true", nil
+		case "false":
+			return "false", nil
+		}
+		return nil, fmt.Errorf("%q is not true or false", raw)
+	case "single_select":
+		if !isAllowed(raw) {
+			return nil, fmt.Errorf("%q is not an allowed value", raw)
+		}
+		return raw, nil
+	case "multi_select":
+		var selected []string
+		for _, v := range strings.FieldsFunc(raw, func(r rune) bool { return r == ';' || r == '|' }) {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			if !isAllowed(v) {
+				return nil, fmt.Errorf("%q is not an allowed value", v)
+			}
+			selected = append(selected, v)
+		}
+		return selected, nil
+	case "url":
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%q is not a valid URL", raw)
+		}
+		return raw, nil
+	default:
+		return raw, nil
+	}
+}
+
+func customPropertyValuesEqual(a, b any) bool {
+	return strings.Join(customPropertyValueStrings(a), "\x00") == strings.Join(customPropertyValueStrings(b), "\x00")
+}
+
+func customPropertyValueStrings(v any) []string {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{val}
+	case []string:
+		return val
+	case []any:
+		var out []string
+		for _, item := range val {
+			out = append(out, fmt.Sprintf("%v", item))
+		}
+		return out
+	default:
+		return []string{fmt.Sprintf("%v", val)}
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+type DefaultBranchAttention struct {
+	Kind   string `json:"kind"` // "protection", "ruleset" or "pull_request"
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+}
+
+type DefaultBranchRenameResult struct {
+	Repo          string                    `json:"repo"`
+	OldName       string                    `json:"old_name"`
+	NewName       string                    `json:"new_name"`
+	Renamed       bool                      `json:"renamed"`
+	RetargetedPRs []int                     `json:"retargeted_prs"`
+	Attention     []*DefaultBranchAttention `json:"attention"`
+	Error         string                    `json:"error"`
+}
+
+type GitHubRepoFieldsUpdatedEvent struct {
+	Org      string         `json:"org"`
+	FullName string         `json:"full_name"`
+	Fields   map[string]any `json:"fields"`
+}
+
+func (ghs *GitHubService) RenameDefaultBranch(owner, repo, newName string) (*DefaultBranchRenameResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if newName == "" {
+		return nil, fmt.Errorf("new branch name is required")
+	}
+	return ghs.renameDefaultBranch(context.Background(), owner, repo, newName), nil
+}
+
+func (ghs *GitHubService) BulkRenameDefaultBranch(fullRepos []string, newName string) ([]*DefaultBranchRenameResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if newName == "" {
+		return nil, fmt.Errorf("new branch name is required")
+	}
+	ctx := context.Background()
+	var results []*DefaultBranchRenameResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &DefaultBranchRenameResult{Repo: fullRepo, NewName: newName, Error: "invalid repository name"})
+			continue
+		}
+		results = append(results, ghs.renameDefaultBranch(ctx, parts[0], parts[1], newName))
+	}
+	return results, nil
+}
+
+// renameDefaultBranch renames the default branch through the API, which moves
+// open pull requests and classic protection along with it, then verifies
+// protection and reports rulesets and pull requests still pointing at the
+// old name.
+func (ghs *GitHubService) renameDefaultBranch(ctx context.Context, owner, repo, newName string) *DefaultBranchRenameResult {
+	result := &DefaultBranchRenameResult{Repo: owner + "/" + repo, NewName: newName}
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.OldName = r.GetDefaultBranch()
+	if result.OldName == "" {
+		result.Error = "repository has no branches"
+		return result
+	}
+	if result.OldName == newName {
+		return result
+	}
+	if _, resp, err := ghs.client.Repositories.GetBranch(ctx, owner, repo, newName, 0); err == nil {
+		result.Error = fmt.Sprintf("branch %s already exists", newName)
+		return result
+	} else if resp == nil || resp.StatusCode != http.StatusNotFound {
+		result.Error = err.Error()
+		return result
+	}
+
+	_, _, err = ghs.client.Repositories.GetBranchProtection(ctx, owner, repo, result.OldName)
+	wasProtected := err == nil
+	if err != nil && !errors.Is(err, github.ErrBranchNotProtected) {
+		result.Attention = append(result.Attention, &DefaultBranchAttention{
+			Kind:   "protection",
+			Name:   result.OldName,
+			Detail: fmt.Sprintf("could not read protection before renaming: %v", err),
+		})
+	}
+	openPRs, err := ghs.listOpenPRsForBase(ctx, owner, repo, result.OldName)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to list open pull requests: %v", err)
+		return result
+	}
+
+	if _, _, err := ghs.client.Repositories.RenameBranch(ctx, owner, repo, result.OldName, newName); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Renamed = true
+	ghs.emitRepoFields(owner, result.Repo, map[string]any{"default_branch": newName})
+
+	if wasProtected {
+		if _, _, err := ghs.client.Repositories.GetBranchProtection(ctx, owner, repo, newName); err != nil {
+			result.Attention = append(result.Attention, &DefaultBranchAttention{
+				Kind:   "protection",
+				Name:   newName,
+				Detail: fmt.Sprintf("%s was protected but %s is not: %v", result.OldName, newName, err),
+			})
+		}
+	}
+
+	if rulesets, err := ghs.fetchRulesets(ctx, owner, repo, true); err == nil {
+		oldRef := "refs/heads/" + result.OldName
+		for _, rs := range rulesets {
+			if (rs.Target != nil && *rs.Target != github.RulesetTargetBranch) || rs.Conditions == nil || rs.Conditions.RefName == nil {
+				continue
+			}
+			if slices.Contains(rs.Conditions.RefName.Include, oldRef) {
+				result.Attention = append(result.Attention, &DefaultBranchAttention{
+					Kind:   "ruleset",
+					Name:   rs.Name,
+					Detail: fmt.Sprintf("targets %s by name and no longer applies to the default branch; use ~DEFAULT_BRANCH or refs/heads/%s (source: %s)", oldRef, newName, rs.Source),
+				})
+			}
+			if slices.Contains(rs.Conditions.RefName.Exclude, oldRef) {
+				result.Attention = append(result.Attention, &DefaultBranchAttention{
+					Kind:   "ruleset",
+					Name:   rs.Name,
+					Detail: fmt.Sprintf("excludes %s by name, so the renamed branch is no longer excluded (source: %s)", oldRef, rs.Source),
+				})
+			}
+		}
+	} else {
+		result.Attention = append(result.Attention, &DefaultBranchAttention{
+			Kind:   "ruleset",
+			Detail: fmt.Sprintf("could not verify rulesets: %v", err),
+		})
+	}
+
+	// Pull requests are retargeted by the rename; any still on the old name
+	// could not be moved.
+	remaining, err := ghs.listOpenPRsForBase(ctx, owner, repo, result.OldName)
+	if err != nil {
+		result.Attention = append(result.Attention, &DefaultBranchAttention{
+			Kind:   "pull_request",
+			Detail: fmt.Sprintf("could not verify that %d open pull requests were retargeted: %v", len(openPRs), err),
+		})
+		return result
+	}
+	for _, pr := range openPRs {
+		if slices.ContainsFunc(remaining, func(p *github.PullRequest) bool { return p.GetNumber() == pr.GetNumber() }) {
+			result.Attention = append(result.Attention, &DefaultBranchAttention{
+				Kind:   "pull_request",
+				Name:   fmt.Sprintf("#%d", pr.GetNumber()),
+				Detail: fmt.Sprintf("%s still targets %s", pr.GetTitle(), result.OldName),
+			})
+			continue
+		}
+		result.RetargetedPRs = append(result.RetargetedPRs, pr.GetNumber())
+	}
+	return result
+}
+
+func (ghs *GitHubService) listOpenPRsForBase(ctx context.Context, owner, repo, base string) ([]*github.PullRequest, error) {
+	opt := &github.PullRequestListOptions{State: "open", Base: base, ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.PullRequest
+	for {
+		prs, resp, err := ghs.client.PullRequests.List(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, prs...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// emitRepoFields updates fields of one repository in the cached repo list
+// without refetching the whole org.
+func (ghs *GitHubService) emitRepoFields(org, fullName string, fields map[string]any) {
+	app := application.Get()
+	if app != nil {
+		app.Event.Emit("github:repo:updated", &GitHubRepoFieldsUpdatedEvent{Org: org, FullName: fullName, Fields: fields})
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+const defaultDeployKeyMaxAgeDays = 365
+
+type GitHubDeployKey struct {
+	ID        int64     `json:"id"`
+	Repo      string    `json:"repo"`
+	Title     string    `json:"title"`
+	ReadOnly  bool      `json:"read_only"`
+	Verified  bool      `json:"verified"`
+	AddedBy   string    `json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"` // Zero when the key was never used
+}
+
+type DeployKeySpec struct {
+	Title    string `json:"title"`
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+type DeployKeyFinding struct {
+	Key     *GitHubDeployKey `json:"key"`
+	Reasons []string         `json:"reasons"` // "write_access", "old" or "unused"
+}
+
+type DeployKeyReport struct {
+	Org        string              `json:"org"`
+	MaxAgeDays int                 `json:"max_age_days"`
+	TotalKeys  int                 `json:"total_keys"`
+	Findings   []*DeployKeyFinding `json:"findings"`
+	Warnings   []string            `json:"warnings"`
+}
+
+type DeployKeyResult struct {
+	Repo     string `json:"repo"`
+	Title    string `json:"title"`
+	OldKeyID int64  `json:"old_key_id"`
+	NewKeyID int64  `json:"new_key_id"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error"`
+}
+
+func (ghs *GitHubService) ListDeployKeys(owner, repo string) ([]*GitHubDeployKey, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.listDeployKeys(context.Background(), owner, repo)
+}
+
+func (ghs *GitHubService) AddDeployKey(owner, repo string, spec *DeployKeySpec) (*GitHubDeployKey, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if spec == nil || strings.TrimSpace(spec.Key) == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	ctx := context.Background()
+	key, _, err := ghs.client.Repositories.CreateKey(ctx, owner, repo, &github.Key{
+		Title:    github.Ptr(spec.Title),
+		Key:      github.Ptr(strings.TrimSpace(spec.Key)),
+		ReadOnly: github.Ptr(spec.ReadOnly),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deployKeyFromKey(owner+"/"+repo, key), nil
+}
+
+func (ghs *GitHubService) DeleteDeployKey(owner, repo string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Repositories.DeleteKey(ctx, owner, repo, id)
+	return err
+}
+
+// BulkDeleteDeployKeys deletes the given keys, identified by Repo and ID as
+// returned by GetDeployKeyReport.
+func (ghs *GitHubService) BulkDeleteDeployKeys(keys []*GitHubDeployKey) ([]*DeployKeyResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*DeployKeyResult
+	for _, k := range keys {
+		result := &DeployKeyResult{Repo: k.Repo, Title: k.Title, OldKeyID: k.ID}
+		results = append(results, result)
+		parts := strings.Split(k.Repo, "/")
+		if len(parts) != 2 {
+			result.Error = "invalid repository name"
+			continue
+		}
+		if _, err := ghs.client.Repositories.DeleteKey(ctx, parts[0], parts[1], k.ID); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Success = true
+	}
+	return results, nil
+}
+
+// GetDeployKeyReport lists the deploy keys across an org's repositories that
+// have write access, are older than maxAgeDays, or have never been used.
+func (ghs *GitHubService) GetDeployKeyReport(org string, maxAgeDays int) (*DeployKeyReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if maxAgeDays <= 0 {
+		maxAgeDays = defaultDeployKeyMaxAgeDays
+	}
+	ctx := context.Background()
+	report := &DeployKeyReport{Org: org, MaxAgeDays: maxAgeDays}
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*GitHubDeployKey
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for _, r := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			repoKeys, err := ghs.listDeployKeys(ctx, org, repo.GetName())
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", repo.GetFullName(), err))
+				return
+			}
+			keys = append(keys, repoKeys...)
+		}(r)
+	}
+	wg.Wait()
+
+	report.TotalKeys = len(keys)
+	oldBefore := time.Now().AddDate(0, 0, -maxAgeDays)
+	for _, k := range keys {
+		var reasons []string
+		if !k.ReadOnly {
+			reasons = append(reasons, "write_access")
+		}
+		if k.CreatedAt.Before(oldBefore) {
+			reasons = append(reasons, "old")
+		}
+		if k.LastUsed.IsZero() {
+			reasons = append(reasons, "unused")
+		}
+		if len(reasons) > 0 {
+			report.Findings = append(report.Findings, &DeployKeyFinding{Key: k, Reasons: reasons})
+		}
+	}
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i].Key, report.Findings[j].Key
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Title < b.Title
+	})
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+// ReplaceDeployKeys rotates the deploy key titled oldTitle in each repository
+// of newKeys. GitHub doesn't allow one public key to be a deploy key on more
+// than one repository, so each repository gets its own new key. The new key
+// is added before the old one is removed; an empty title keeps the old one.
+func (ghs *GitHubService) ReplaceDeployKeys(oldTitle string, newKeys map[string]*DeployKeySpec) ([]*DeployKeyResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*DeployKeyResult
+	for _, fullRepo := range slices.Sorted(maps.Keys(newKeys)) {
+		spec := newKeys[fullRepo]
+		result := &DeployKeyResult{Repo: fullRepo, Title: oldTitle}
+		results = append(results, result)
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			result.Error = "invalid repository name"
+			continue
+		}
+		if spec == nil || strings.TrimSpace(spec.Key) == "" {
+			result.Error = "no replacement key given"
+			continue
+		}
+		keys, err := ghs.listDeployKeys(ctx, parts[0], parts[1])
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		idx := slices.IndexFunc(keys, func(k *GitHubDeployKey) bool { return k.Title == oldTitle })
+		if idx == -1 {
+			result.Error = fmt.Sprintf("no deploy key titled %q", oldTitle)
+			continue
+		}
+		old := keys[idx]
+		result.OldKeyID = old.ID
+
+		title := spec.Title
+		if title == "" {
+			title = old.Title
+		}
+		created, _, err := ghs.client.Repositories.CreateKey(ctx, parts[0], parts[1], &github.Key{
+			Title:    github.Ptr(title),
+			Key:      github.Ptr(strings.TrimSpace(spec.Key)),
+			ReadOnly: github.Ptr(spec.ReadOnly),
+		})
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to add new key: %v", err)
+			continue
+		}
+		result.NewKeyID = created.GetID()
+		result.Title = title
+		if _, err := ghs.client.Repositories.DeleteKey(ctx, parts[0], parts[1], old.ID); err != nil {
+			result.Error = fmt.Sprintf("new key added but failed to remove old key: %v", err)
+			continue
+		}
+		result.Success = true
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) listDeployKeys(ctx context.Context, owner, repo string) ([]*GitHubDeployKey, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*GitHubDeployKey
+	for {
+		keys, resp, err := ghs.client.Repositories.ListKeys(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			all = append(all, deployKeyFromKey(owner+"/"+repo, k))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func deployKeyFromKey(fullRepo string, k *github.Key) *GitHubDeployKey {
+	return &GitHubDeployKey{
+		ID:        k.GetID(),
+		Repo:      fullRepo,
+		Title:     k.GetTitle(),
+		ReadOnly:  k.GetReadOnly(),
+		Verified:  k.GetVerified(),
+		AddedBy:   k.GetAddedBy(),
+		CreatedAt: k.GetCreatedAt().Time,
+		LastUsed:  k.GetLastUsed().Time,
+	}
+}
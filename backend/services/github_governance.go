@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type CloneGovernanceOptions struct {
+	Mode              string `json:"mode"` // "merge" or "replace"
+	IncludeProtection bool   `json:"include_protection"`
+	IncludeRulesets   bool   `json:"include_rulesets"`
+	MapDefaultBranch  bool   `json:"map_default_branch"`
+	DryRun            bool   `json:"dry_run"`
+}
+
+type GovernanceChange struct {
+	Kind   string `json:"kind"`   // "protection" or "ruleset"
+	Name   string `json:"name"`   // branch name or ruleset name
+	Action string `json:"action"` // "create", "update", "delete" or "unchanged"
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+	Error  string `json:"error"`
+}
+
+type CloneGovernanceResult struct {
+	Repo    string              `json:"repo"`
+	Changes []*GovernanceChange `json:"changes"`
+	Error   string              `json:"error"`
+}
+
+// governanceSnapshot is the protection and repository-level rulesets of a
+// repository, in the request shape that can be sent back to the API.
+type governanceSnapshot struct {
+	DefaultBranch string
+	Protection    map[string]*github.ProtectionRequest
+	Signatures    map[string]bool
+	Rulesets      map[string]*github.RepositoryRuleset
+	RulesetIDs    map[string]int64
+}
+
+// CloneGovernance copies classic branch protection and repository rulesets
+// from sourceRepo onto each target. In "replace" mode anything on the target
+// that the source does not have is removed; "merge" only adds and updates.
+func (ghs *GitHubService) CloneGovernance(sourceRepo string, targetRepos []string, options *CloneGovernanceOptions) ([]*CloneGovernanceResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if options == nil {
+		options = &CloneGovernanceOptions{Mode: "merge", IncludeProtection: true, IncludeRulesets: true}
+	}
+	if options.Mode != "merge" && options.Mode != "replace" {
+		return nil, fmt.Errorf("invalid mode")
+	}
+	ctx := context.Background()
+
+	parts := strings.Split(sourceRepo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid source repository %q", sourceRepo)
+	}
+	source, err := ghs.governanceSnapshot(ctx, parts[0], parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source governance: %w", err)
+	}
+
+	var results []*CloneGovernanceResult
+	for _, fullRepo := range targetRepos {
+		result := &CloneGovernanceResult{Repo: fullRepo}
+		results = append(results, result)
+
+		if strings.EqualFold(fullRepo, sourceRepo) {
+			result.Error = "target is the source repository"
+			continue
+		}
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			result.Error = "invalid repository name"
+			continue
+		}
+		owner, repo := parts[0], parts[1]
+
+		target, err := ghs.governanceSnapshot(ctx, owner, repo)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+
+		if options.IncludeProtection {
+			result.Changes = append(result.Changes, ghs.cloneProtection(ctx, owner, repo, source, target, options)...)
+		}
+		if options.IncludeRulesets {
+			result.Changes = append(result.Changes, ghs.cloneRulesets(ctx, owner, repo, source, target, options)...)
+		}
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) cloneProtection(ctx context.Context, owner, repo string, source, target *governanceSnapshot, options *CloneGovernanceOptions) []*GovernanceChange {
+	var changes []*GovernanceChange
+	applied := make(map[string]bool)
+
+	for _, branch := range slices.Sorted(maps.Keys(source.Protection)) {
+		protection := source.Protection[branch]
+		targetBranch := branch
+		if options.MapDefaultBranch && branch == source.DefaultBranch {
+			targetBranch = target.DefaultBranch
+		}
+		applied[targetBranch] = true
+
+		change := &GovernanceChange{
+			Kind:   "protection",
+			Name:   targetBranch,
+			Before: target.Protection[targetBranch],
+			After:  protection,
+		}
+		changes = append(changes, change)
+
+		switch {
+		case target.Protection[targetBranch] == nil:
+			change.Action = "create"
+		case governanceEqual(target.Protection[targetBranch], protection) && target.Signatures[targetBranch] == source.Signatures[branch]:
+			change.Action = "unchanged"
+			continue
+		default:
+			change.Action = "update"
+		}
+
+		if options.DryRun {
+			continue
+		}
+		_, _, err := ghs.client.Repositories.UpdateBranchProtection(ctx, owner, repo, targetBranch, protection)
+		if err != nil {
+			change.Error = err.Error()
+			continue
+		}
+		if source.Signatures[branch] {
+			_, _, err = ghs.client.Repositories.RequireSignaturesOnProtectedBranch(ctx, owner, repo, targetBranch)
+		} else if target.Signatures[targetBranch] {
+			_, err = ghs.client.Repositories.OptionalSignaturesOnProtectedBranch(ctx, owner, repo, targetBranch)
+		}
+		if err != nil {
+			change.Error = err.Error()
+		}
+	}
+
+	if options.Mode == "replace" {
+		for _, branch := range slices.Sorted(maps.Keys(target.Protection)) {
+			protection := target.Protection[branch]
+			if applied[branch] {
+				continue
+			}
+			change := &GovernanceChange{
+				Kind:   "protection",
+				Name:   branch,
+				Action: "delete",
+				Before: protection,
+			}
+			changes = append(changes, change)
+			if options.DryRun {
+				continue
+			}
+			if _, err := ghs.client.Repositories.RemoveBranchProtection(ctx, owner, repo, branch); err != nil {
+				change.Error = err.Error()
+			}
+		}
+	}
+	return changes
+}
+
+func (ghs *GitHubService) cloneRulesets(ctx context.Context, owner, repo string, source, target *governanceSnapshot, options *CloneGovernanceOptions) []*GovernanceChange {
+	var changes []*GovernanceChange
+
+	for _, name := range slices.Sorted(maps.Keys(source.Rulesets)) {
+		ruleset := source.Rulesets[name]
+		change := &GovernanceChange{
+			Kind:   "ruleset",
+			Name:   name,
+			Before: target.Rulesets[name],
+			After:  ruleset,
+		}
+		changes = append(changes, change)
+
+		switch {
+		case target.Rulesets[name] == nil:
+			change.Action = "create"
+		case governanceEqual(target.Rulesets[name], ruleset):
+			change.Action = "unchanged"
+			continue
+		default:
+			change.Action = "update"
+		}
+
+		if options.DryRun {
+			continue
+		}
+		var err error
+		if change.Action == "create" {
+			_, _, err = ghs.client.Repositories.CreateRuleset(ctx, owner, repo, *ruleset)
+		} else {
+			_, _, err = ghs.client.Repositories.UpdateRuleset(ctx, owner, repo, target.RulesetIDs[name], *ruleset)
+		}
+		if err != nil {
+			change.Error = err.Error()
+		}
+	}
+
+	if options.Mode == "replace" {
+		for _, name := range slices.Sorted(maps.Keys(target.Rulesets)) {
+			ruleset := target.Rulesets[name]
+			if source.Rulesets[name] != nil {
+				continue
+			}
+			change := &GovernanceChange{
+				Kind:   "ruleset",
+				Name:   name,
+				Action: "delete",
+				Before: ruleset,
+			}
+			changes = append(changes, change)
+			if options.DryRun {
+				continue
+			}
+			if _, err := ghs.client.Repositories.DeleteRuleset(ctx, owner, repo, target.RulesetIDs[name]); err != nil {
+				change.Error = err.Error()
+			}
+		}
+	}
+	return changes
+}
+
+// governanceSnapshot reads protection and rulesets the same way
+// GetRepoDetails does, but only keeps rulesets defined on the repository
+// itself since inherited ones cannot be copied per repo.
+func (ghs *GitHubService) governanceSnapshot(ctx context.Context, owner, repo string) (*governanceSnapshot, error) {
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &governanceSnapshot{
+		DefaultBranch: r.GetDefaultBranch(),
+		Protection:    make(map[string]*github.ProtectionRequest),
+		Signatures:    make(map[string]bool),
+		Rulesets:      make(map[string]*github.RepositoryRuleset),
+		RulesetIDs:    make(map[string]int64),
+	}
+
+	protections, err := ghs.fetchBranchProtections(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range protections {
+		snapshot.Protection[p.BranchName] = protectionToRequest(p.Protection)
+		snapshot.Signatures[p.BranchName] = p.Protection.GetRequiredSignatures().GetEnabled()
+	}
+
+	rulesets, err := ghs.fetchRulesets(ctx, owner, repo, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range rulesets {
+		if rs.SourceType != nil && *rs.SourceType != github.RulesetSourceTypeRepository {
+			continue
+		}
+		clean, err := sanitizeRuleset(rs)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Rulesets[rs.Name] = clean
+		snapshot.RulesetIDs[rs.Name] = rs.GetID()
+	}
+	return snapshot, nil
+}
+
+// protectionToRequest converts the read model returned by GetBranchProtection
+// into the request accepted by UpdateBranchProtection. Users, teams and apps
+// are reduced to their logins and slugs. Required signatures have their own
+// endpoint and are not part of the request.
+func protectionToRequest(p *github.Protection) *github.ProtectionRequest {
+	if p == nil {
+		return nil
+	}
+	req := &github.ProtectionRequest{
+		EnforceAdmins: p.EnforceAdmins != nil && p.EnforceAdmins.Enabled,
+	}
+
+	if rsc := p.GetRequiredStatusChecks(); rsc != nil {
+		checks := &github.RequiredStatusChecks{Strict: rsc.Strict}
+		if rsc.Checks != nil {
+			checks.Checks = rsc.Checks
+		} else if rsc.Contexts != nil {
+			checks.Contexts = rsc.Contexts
+		} else {
+			checks.Checks = &[]*github.RequiredStatusCheck{}
+		}
+		req.RequiredStatusChecks = checks
+	}
+
+	if prr := p.GetRequiredPullRequestReviews(); prr != nil {
+		reviews := &github.PullRequestReviewsEnforcementRequest{
+			DismissStaleReviews:          prr.DismissStaleReviews,
+			RequireCodeOwnerReviews:      prr.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: prr.RequiredApprovingReviewCount,
+			RequireLastPushApproval:      github.Ptr(prr.RequireLastPushApproval),
+		}
+		if dr := prr.DismissalRestrictions; dr != nil {
+			users, teams, apps := actorNames(dr.Users, dr.Teams, dr.Apps)
+			reviews.DismissalRestrictionsRequest = &github.DismissalRestrictionsRequest{
+				Users: &users,
+				Teams: &teams,
+				Apps:  &apps,
+			}
+		}
+		if bp := prr.BypassPullRequestAllowances; bp != nil {
+			users, teams, apps := actorNames(bp.Users, bp.Teams, bp.Apps)
+			reviews.BypassPullRequestAllowancesRequest = &github.BypassPullRequestAllowancesRequest{
+				Users: users,
+				Teams: teams,
+				Apps:  apps,
+			}
+		}
+		req.RequiredPullRequestReviews = reviews
+	}
+
+	if r := p.GetRestrictions(); r != nil {
+		users, teams, apps := actorNames(r.Users, r.Teams, r.Apps)
+		req.Restrictions = &github.BranchRestrictionsRequest{
+			Users: users,
+			Teams: teams,
+			Apps:  apps,
+		}
+	}
+
+	if p.RequireLinearHistory != nil {
+		req.RequireLinearHistory = github.Ptr(p.RequireLinearHistory.Enabled)
+	}
+	if p.AllowForcePushes != nil {
+		req.AllowForcePushes = github.Ptr(p.AllowForcePushes.Enabled)
+	}
+	if p.AllowDeletions != nil {
+		req.AllowDeletions = github.Ptr(p.AllowDeletions.Enabled)
+	}
+	if p.RequiredConversationResolution != nil {
+		req.RequiredConversationResolution = github.Ptr(p.RequiredConversationResolution.Enabled)
+	}
+	if p.BlockCreations != nil {
+		req.BlockCreations = p.BlockCreations.Enabled
+	}
+	if p.LockBranch != nil {
+		req.LockBranch = p.LockBranch.Enabled
+	}
+	if p.AllowForkSyncing != nil {
+		req.AllowForkSyncing = p.AllowForkSyncing.Enabled
+	}
+	return req
+}
+
+func actorNames(users []*github.User, teams []*github.Team, apps []*github.App) ([]string, []string, []string) {
+	userNames := []string{}
+	for _, u := range users {
+		userNames = append(userNames, u.GetLogin())
+	}
+	teamNames := []string{}
+	for _, t := range teams {
+		teamNames = append(teamNames, t.GetSlug())
+	}
+	appNames := []string{}
+	for _, a := range apps {
+		appNames = append(appNames, a.GetSlug())
+	}
+	return userNames, teamNames, appNames
+}
+
+// governanceEqual compares two API payloads by their JSON encoding.
+func governanceEqual(a, b any) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aj) == string(bj)
+}
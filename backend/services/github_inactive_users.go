@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+const defaultInactiveDays = 90
+
+type InactiveUser struct {
+	Login          string    `json:"login"`
+	Kind           string    `json:"kind"` // "member" or "outside"
+	Role           string    `json:"role"`
+	Teams          []string  `json:"teams"`
+	LastActivity   time.Time `json:"last_activity"` // Zero when no activity was found
+	ActivitySource string    `json:"activity_source"`
+	Error          string    `json:"error"` // Why activity couldn't be checked, for unverified users
+}
+
+type InactiveUsersReport struct {
+	Org        string          `json:"org"`
+	Days       int             `json:"days"`
+	Since      time.Time       `json:"since"`
+	Users      []*InactiveUser `json:"users"`
+	Unverified []*InactiveUser `json:"unverified"` // Activity lookup failed; never reported as inactive
+	Warnings   []string        `json:"warnings"`
+}
+
+type InactiveRemovalResult struct {
+	Login   string `json:"login"`
+	Action  string `json:"action"` // "remove_from_team", "remove_member" or "remove_outside_collaborator"
+	Target  string `json:"target"` // Team slug or org name
+	Applied bool   `json:"applied"`
+	Error   string `json:"error"`
+}
+
+// userActivity tracks the most recent activity seen for each login, and the
+// logins whose activity couldn't be looked up.
+type userActivity struct {
+	mu     sync.Mutex
+	last   map[string]time.Time
+	src    map[string]string
+	failed map[string]string
+}
+
+func newUserActivity() *userActivity {
+	return &userActivity{last: make(map[string]time.Time), src: make(map[string]string), failed: make(map[string]string)}
+}
+
+func (a *userActivity) fail(login string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failed[login] = err.Error()
+}
+
+func (a *userActivity) record(login string, at time.Time, source string) {
+	if login == "" || at.IsZero() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if at.After(a.last[login]) {
+		a.last[login] = at
+		a.src[login] = source
+	}
+}
+
+// GetInactiveUsersReport lists org members and outside collaborators with no
+// activity in the last days. Activity is taken from the user's recent events
+// in the org, commits to the org's repositories and team changes in the audit
+// log, where the plan exposes it. Users whose events can't be read are listed
+// as unverified, and the report fails if commits can't be read.
+func (ghs *GitHubService) GetInactiveUsersReport(org string, days int) (*InactiveUsersReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if days <= 0 {
+		days = defaultInactiveDays
+	}
+	ctx := context.Background()
+	since := time.Now().AddDate(0, 0, -days)
+	report := &InactiveUsersReport{Org: org, Days: days, Since: since}
+
+	members, err := ghs.listOrgMembers(ctx, org, "all", "")
+	if err != nil {
+		return nil, err
+	}
+	admins := make(map[string]bool)
+	if owners, err := ghs.listOrgMembers(ctx, org, "admin", ""); err == nil {
+		for _, u := range owners {
+			admins[u.GetLogin()] = true
+		}
+	}
+	users := make(map[string]*InactiveUser)
+	for _, u := range members {
+		role := "member"
+		if admins[u.GetLogin()] {
+			role = "admin"
+		}
+		users[u.GetLogin()] = &InactiveUser{Login: u.GetLogin(), Kind: "member", Role: role}
+	}
+	outsideOpt := &github.ListOutsideCollaboratorsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		outside, resp, err := ghs.client.Organizations.ListOutsideCollaborators(ctx, org, outsideOpt)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list outside collaborators: %v", err))
+			break
+		}
+		for _, u := range outside {
+			users[u.GetLogin()] = &InactiveUser{Login: u.GetLogin(), Kind: "outside"}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		outsideOpt.Page = resp.NextPage
+	}
+
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	activity := newUserActivity()
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	var commitErrs []string
+	var commitErrsMu sync.Mutex
+
+	for login := range users {
+		wg.Add(1)
+		go func(login string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			events, _, err := ghs.client.Activity.ListEventsPerformedByUser(ctx, login, false, &github.ListOptions{PerPage: 100})
+			if err != nil {
+				activity.fail(login, err)
+				return
+			}
+			for _, e := range events {
+				if strings.HasPrefix(e.GetRepo().GetName(), org+"/") || e.GetOrg().GetLogin() == org {
+					activity.record(login, e.GetCreatedAt().Time, "event")
+				}
+			}
+		}(login)
+	}
+
+	for _, r := range repos {
+		if r.GetPushedAt().Time.Before(since) {
+			continue
+		}
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			opt := &github.CommitsListOptions{Since: since, ListOptions: github.ListOptions{PerPage: 100}}
+			for {
+				commits, resp, err := ghs.client.Repositories.ListCommits(ctx, org, repo.GetName(), opt)
+				if err != nil {
+					// An empty repository has no commits to list.
+					if resp != nil && resp.StatusCode == http.StatusConflict {
+						return
+					}
+					commitErrsMu.Lock()
+					commitErrs = append(commitErrs, fmt.Sprintf("%s: %v", repo.GetFullName(), err))
+					commitErrsMu.Unlock()
+					return
+				}
+				for _, c := range commits {
+					activity.record(c.GetAuthor().GetLogin(), c.GetCommit().GetAuthor().GetDate().Time, "commit")
+					activity.record(c.GetCommitter().GetLogin(), c.GetCommit().GetCommitter().GetDate().Time, "commit")
+				}
+				if resp.NextPage == 0 {
+					return
+				}
+				opt.Page = resp.NextPage
+			}
+		}(r)
+	}
+	wg.Wait()
+	if len(commitErrs) > 0 {
+		sort.Strings(commitErrs)
+		return nil, fmt.Errorf("failed to list commits, activity can't be verified: %s", strings.Join(commitErrs, "; "))
+	}
+
+	if err := ghs.recordTeamChanges(ctx, org, since, activity); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("audit log unavailable, team changes not considered: %v", err))
+	}
+
+	teams, err := ghs.listOrgTeams(ctx, org)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list teams: %v", err))
+	}
+	userTeams := make(map[string][]string)
+	for _, t := range teams {
+		members, err := ghs.listTeamMembers(ctx, org, t.GetSlug())
+		if err != nil {
+			continue
+		}
+		for _, m := range members {
+			userTeams[m.GetLogin()] = append(userTeams[m.GetLogin()], t.GetSlug())
+		}
+	}
+
+	for login, u := range users {
+		u.Teams = userTeams[login]
+		sort.Strings(u.Teams)
+	}
+	report.Users, report.Unverified = classifyInactiveUsers(users, activity, since)
+	return report, nil
+}
+
+// classifyInactiveUsers splits users into those with no activity since the
+// cutoff and those whose activity couldn't be looked up. Users active since
+// the cutoff are dropped.
+func classifyInactiveUsers(users map[string]*InactiveUser, activity *userActivity, since time.Time) (inactive, unverified []*InactiveUser) {
+	for login, u := range users {
+		last := activity.last[login]
+		if last.After(since) {
+			continue
+		}
+		u.LastActivity = last
+		u.ActivitySource = activity.src[login]
+		if msg, ok := activity.failed[login]; ok {
+			u.Error = msg
+			unverified = append(unverified, u)
+			continue
+		}
+		inactive = append(inactive, u)
+	}
+	byLogin := func(list []*InactiveUser) {
+		sort.Slice(list, func(i, j int) bool { return strings.ToLower(list[i].Login) < strings.ToLower(list[j].Login) })
+	}
+	byLogin(inactive)
+	byLogin(unverified)
+	return inactive, unverified
+}
+
+// ExportInactiveUsersCSV returns the inactive users report as CSV.
+func (ghs *GitHubService) ExportInactiveUsersCSV(org string, days int) (string, error) {
+	report, err := ghs.GetInactiveUsersReport(org, days)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"org", "login", "kind", "role", "teams", "last_activity", "activity_source", "window_days"})
+	for _, u := range report.Users {
+		last := ""
+		if !u.LastActivity.IsZero() {
+			last = u.LastActivity.Format(time.RFC3339)
+		}
+		w.Write([]string{org, u.Login, u.Kind, u.Role, strings.Join(u.Teams, ";"), last, u.ActivitySource, strconv.Itoa(report.Days)})
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// RemoveInactiveUsers removes users from all their teams (mode "teams") or
+// from the org entirely (mode "org"). With dryRun the planned removals are
+// returned without being applied.
+func (ghs *GitHubService) RemoveInactiveUsers(org string, logins []string, mode string, dryRun bool) ([]*InactiveRemovalResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if mode != "teams" && mode != "org" {
+		return nil, fmt.Errorf("invalid mode %q", mode)
+	}
+	ctx := context.Background()
+	var results []*InactiveRemovalResult
+	for _, login := range logins {
+		membership, resp, err := ghs.client.Organizations.GetOrgMembership(ctx, login, org)
+		outside := err != nil && resp != nil && resp.StatusCode == http.StatusNotFound
+		if err != nil && !outside {
+			results = append(results, &InactiveRemovalResult{Login: login, Target: org, Error: err.Error()})
+			continue
+		}
+
+		if mode == "org" {
+			result := &InactiveRemovalResult{Login: login, Action: "remove_member", Target: org}
+			if outside {
+				result.Action = "remove_outside_collaborator"
+			}
+			results = append(results, result)
+			if dryRun {
+				continue
+			}
+			if outside {
+				_, err = ghs.client.Organizations.RemoveOutsideCollaborator(ctx, org, login)
+			} else {
+				_, err = ghs.client.Organizations.RemoveMember(ctx, org, login)
+			}
+			if err != nil {
+				result.Error = err.Error()
+				continue
+			}
+			result.Applied = true
+			continue
+		}
+
+		if outside {
+			results = append(results, &InactiveRemovalResult{Login: login, Action: "remove_from_team", Target: org, Error: "outside collaborators aren't team members"})
+			continue
+		}
+		if membership.GetState() != "active" {
+			results = append(results, &InactiveRemovalResult{Login: login, Action: "remove_from_team", Target: org, Error: fmt.Sprintf("membership is %s, not active", membership.GetState())})
+			continue
+		}
+		teams, warnings, err := ghs.userTeams(ctx, org, login)
+		if err != nil {
+			results = append(results, &InactiveRemovalResult{Login: login, Action: "remove_from_team", Error: err.Error()})
+			continue
+		}
+		for _, w := range warnings {
+			results = append(results, &InactiveRemovalResult{Login: login, Action: "remove_from_team", Target: org, Error: w})
+		}
+		for _, t := range teams {
+			result := &InactiveRemovalResult{Login: login, Action: "remove_from_team", Target: t.team.GetSlug()}
+			results = append(results, result)
+			if dryRun {
+				continue
+			}
+			if _, err := ghs.client.Teams.RemoveTeamMembershipBySlug(ctx, org, t.team.GetSlug(), login); err != nil {
+				result.Error = err.Error()
+				continue
+			}
+			result.Applied = true
+		}
+	}
+	return results, nil
+}
+
+// recordTeamChanges records team changes from the org audit log as activity
+// of both the actor and the affected user. The audit log API requires GitHub
+// Enterprise Cloud.
+func (ghs *GitHubService) recordTeamChanges(ctx context.Context, org string, since time.Time, activity *userActivity) error {
+	opt := &github.GetAuditLogOptions{
+		Phrase:            github.Ptr("action:team created:>=" + since.Format("2006-01-02")),
+		ListCursorOptions: github.ListCursorOptions{PerPage: 100},
+	}
+	for {
+		entries, resp, err := ghs.client.Organizations.GetAuditLog(ctx, org, opt)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			at := e.GetCreatedAt().Time
+			if at.IsZero() {
+				at = e.GetTimestamp().Time
+			}
+			activity.record(e.GetActor(), at, "team_change")
+			activity.record(e.GetUser(), at, "team_change")
+		}
+		if resp.After == "" {
+			return nil
+		}
+		opt.After = resp.After
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifyInactiveUsers(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -90)
+
+	tests := []struct {
+		name           string
+		recorded       *time.Time
+		failed         bool
+		wantInactive   bool
+		wantUnverified bool
+	}{
+		{name: "recent activity", recorded: ptrTime(now.AddDate(0, 0, -1))},
+		{name: "old activity", recorded: ptrTime(now.AddDate(0, 0, -200)), wantInactive: true},
+		{name: "no activity", wantInactive: true},
+		{name: "lookup failed", failed: true, wantUnverified: true},
+		{name: "lookup failed but active through commits", recorded: ptrTime(now.AddDate(0, 0, -1)), failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := map[string]*InactiveUser{"octocat": {Login: "octocat", Kind: "member"}}
+			activity := newUserActivity()
+			if tt.recorded != nil {
+				activity.record("octocat", *tt.recorded, "commit")
+			}
+			if tt.failed {
+				activity.fail("octocat", errors.New("rate limited"))
+			}
+			inactive, unverified := classifyInactiveUsers(users, activity, since)
+			if got := len(inactive) == 1; got != tt.wantInactive {
+				t.Errorf("inactive = %v, want %v", got, tt.wantInactive)
+			}
+			if got := len(unverified) == 1; got != tt.wantUnverified {
+				t.Errorf("unverified = %v, want %v", got, tt.wantUnverified)
+			}
+			if tt.wantUnverified && unverified[0].Error == "" {
+				t.Errorf("unverified user has no error")
+			}
+		})
+	}
+}
+
+func TestClassifyInactiveUsersSorted(t *testing.T) {
+	users := map[string]*InactiveUser{
+		"zed":   {Login: "zed"},
+		"Alice": {Login: "Alice"},
+		"bob":   {Login: "bob"},
+	}
+	inactive, _ := classifyInactiveUsers(users, newUserActivity(), time.Now())
+	var got []string
+	for _, u := range inactive {
+		got = append(got, u.Login)
+	}
+	want := []string{"Alice", "bob", "zed"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
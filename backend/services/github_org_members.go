@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type GitHubOrgMember struct {
+	Login     string `json:"login"`
+	AvatarUrl string `json:"avatar_url"`
+	Url       string `json:"url"`
+	Role      string `json:"role"`       // "admin" (owner) or "member"
+	TwoFactor *bool  `json:"two_factor"` // nil when 2FA status isn't visible to the authenticated user
+}
+
+type GitHubOrgInvitation struct {
+	ID        int64     `json:"id"`
+	Login     string    `json:"login"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Inviter   string    `json:"inviter"`
+	TeamCount int       `json:"team_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListOrgMembers lists the members of an org with their role. 2FA status is
+// only filled in when the authenticated user is an org owner.
+func (ghs *GitHubService) ListOrgMembers(org string) ([]*GitHubOrgMember, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	users, err := ghs.listOrgMembers(ctx, org, "all", "")
+	if err != nil {
+		return nil, err
+	}
+	owners, err := ghs.listOrgMembers(ctx, org, "admin", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list org owners: %w", err)
+	}
+	admins := make(map[string]bool)
+	for _, u := range owners {
+		admins[u.GetLogin()] = true
+	}
+	// Only owners may filter on 2FA; for anyone else the status stays unknown.
+	var without2FA map[string]bool
+	if disabled, err := ghs.listOrgMembers(ctx, org, "all", "2fa_disabled"); err == nil {
+		without2FA = make(map[string]bool)
+		for _, u := range disabled {
+			without2FA[u.GetLogin()] = true
+		}
+	}
+
+	var members []*GitHubOrgMember
+	for _, u := range users {
+		member := &GitHubOrgMember{
+			Login:     u.GetLogin(),
+			AvatarUrl: u.GetAvatarURL(),
+			Url:       u.GetHTMLURL(),
+			Role:      "member",
+		}
+		if admins[member.Login] {
+			member.Role = "admin"
+		}
+		if without2FA != nil {
+			member.TwoFactor = github.Ptr(!without2FA[member.Login])
+		}
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return strings.ToLower(members[i].Login) < strings.ToLower(members[j].Login) })
+	return members, nil
+}
+
+func (ghs *GitHubService) ListOrgInvitations(org string) ([]*GitHubOrgInvitation, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*GitHubOrgInvitation
+	for {
+		invitations, resp, err := ghs.client.Organizations.ListPendingOrgInvitations(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, inv := range invitations {
+			all = append(all, orgInvitation(inv))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// InviteOrgMember invites a user, given by login or email address, to the
+// org. role is "admin", "direct_member" or "billing_manager"; teamSlugs are
+// joined once the invitation is accepted.
+func (ghs *GitHubService) InviteOrgMember(org, invitee, role string, teamSlugs []string) (*GitHubOrgInvitation, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	opts := &github.CreateOrgInvitationOptions{}
+	if role != "" {
+		opts.Role = github.Ptr(role)
+	}
+	if strings.Contains(invitee, "@") {
+		opts.Email = github.Ptr(invitee)
+	} else {
+		user, _, err := ghs.client.Users.Get(ctx, invitee)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %w", invitee, err)
+		}
+		opts.InviteeID = github.Ptr(user.GetID())
+	}
+	for _, slug := range teamSlugs {
+		team, _, err := ghs.client.Teams.GetTeamBySlug(ctx, org, slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up team %s: %w", slug, err)
+		}
+		opts.TeamID = append(opts.TeamID, team.GetID())
+	}
+
+	inv, _, err := ghs.client.Organizations.CreateOrgInvitation(ctx, org, opts)
+	if err != nil {
+		return nil, err
+	}
+	return orgInvitation(inv), nil
+}
+
+func (ghs *GitHubService) CancelOrgInvitation(org string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Organizations.CancelInvite(ctx, org, id)
+	return err
+}
+
+// RemoveOrgMember removes a user from the org, including all team
+// memberships and access to private repositories.
+func (ghs *GitHubService) RemoveOrgMember(org, login string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Organizations.RemoveMember(ctx, org, login)
+	return err
+}
+
+// SetOrgMemberRole changes a member's role to "admin" (owner) or "member".
+func (ghs *GitHubService) SetOrgMemberRole(org, login, role string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	if role != "admin" && role != "member" {
+		return fmt.Errorf("invalid role %q", role)
+	}
+	ctx := context.Background()
+	_, _, err := ghs.client.Organizations.EditOrgMembership(ctx, login, org, &github.Membership{Role: github.Ptr(role)})
+	return err
+}
+
+// ConvertToOutsideCollaborator removes a member from the org while keeping
+// their access to the repositories they can reach through teams as direct
+// outside collaborator grants.
+func (ghs *GitHubService) ConvertToOutsideCollaborator(org, login string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Organizations.ConvertMemberToOutsideCollaborator(ctx, org, login)
+	return err
+}
+
+func orgInvitation(inv *github.Invitation) *GitHubOrgInvitation {
+	return &GitHubOrgInvitation{
+		ID:        inv.GetID(),
+		Login:     inv.GetLogin(),
+		Email:     inv.GetEmail(),
+		Role:      inv.GetRole(),
+		Inviter:   inv.GetInviter().GetLogin(),
+		TeamCount: inv.GetTeamCount(),
+		CreatedAt: inv.GetCreatedAt().Time,
+	}
+}
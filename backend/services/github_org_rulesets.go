@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type GitHubInheritedRuleset struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Source      string   `json:"source"`
+	SourceType  string   `json:"source_type"`
+	Enforcement string   `json:"enforcement"`
+	RuleTypes   []string `json:"rule_types"`
+}
+
+func (ghs *GitHubService) ListOrgRulesets(org string) ([]*github.RepositoryRuleset, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*github.RepositoryRuleset
+	for {
+		rulesets, resp, err := ghs.client.Organizations.GetAllRepositoryRulesets(ctx, org, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rulesets...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) GetOrgRuleset(org string, id int64) (*github.RepositoryRuleset, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	ruleset, _, err := ghs.client.Organizations.GetRepositoryRuleset(ctx, org, id)
+	return ruleset, err
+}
+
+func (ghs *GitHubService) CreateOrgRuleset(org string, ruleset *github.RepositoryRuleset) (*github.RepositoryRuleset, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if err := validateOrgRulesetConditions(ruleset); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	created, _, err := ghs.client.Organizations.CreateRepositoryRuleset(ctx, org, *ruleset)
+	return created, err
+}
+
+func (ghs *GitHubService) UpdateOrgRuleset(org string, id int64, ruleset *github.RepositoryRuleset) (*github.RepositoryRuleset, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if err := validateOrgRulesetConditions(ruleset); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	updated, _, err := ghs.client.Organizations.UpdateRepositoryRuleset(ctx, org, id, *ruleset)
+	return updated, err
+}
+
+func (ghs *GitHubService) DeleteOrgRuleset(org string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	_, err := ghs.client.Organizations.DeleteRepositoryRuleset(ctx, org, id)
+	return err
+}
+
+// validateOrgRulesetConditions checks that an org ruleset selects its
+// repositories in exactly one way: by name pattern, by ID or by custom
+// property. Push rulesets don't target refs, so only ref-based targets
+// require a ref_name condition.
+func validateOrgRulesetConditions(ruleset *github.RepositoryRuleset) error {
+	if ruleset == nil {
+		return fmt.Errorf("ruleset is required")
+	}
+	if ruleset.Name == "" {
+		return fmt.Errorf("ruleset name is required")
+	}
+	c := ruleset.Conditions
+	if c == nil {
+		return fmt.Errorf("organization rulesets require repository conditions")
+	}
+	selectors := 0
+	if c.RepositoryName != nil {
+		selectors++
+	}
+	if c.RepositoryID != nil {
+		selectors++
+	}
+	if c.RepositoryProperty != nil {
+		selectors++
+		for _, p := range slices.Concat(c.RepositoryProperty.Include, c.RepositoryProperty.Exclude) {
+			if p.Name == "" {
+				return fmt.Errorf("repository property conditions require a property name")
+			}
+		}
+	}
+	if selectors != 1 {
+		return fmt.Errorf("set exactly one of repository name, repository ID or repository property conditions")
+	}
+	target := github.RulesetTargetBranch
+	if ruleset.Target != nil {
+		target = *ruleset.Target
+	}
+	if (target == github.RulesetTargetBranch || target == github.RulesetTargetTag) && c.RefName == nil {
+		return fmt.Errorf("branch and tag rulesets require a ref name condition")
+	}
+	return nil
+}
+
+// inheritedRulesets picks out the rulesets that a repository receives from its
+// organization or enterprise rather than defining itself.
+func inheritedRulesets(rulesets []*github.RepositoryRuleset) []*GitHubInheritedRuleset {
+	var inherited []*GitHubInheritedRuleset
+	for _, rs := range rulesets {
+		if rs.SourceType == nil || *rs.SourceType == github.RulesetSourceTypeRepository {
+			continue
+		}
+		inherited = append(inherited, &GitHubInheritedRuleset{
+			ID:          rs.GetID(),
+			Name:        rs.Name,
+			Source:      rs.Source,
+			SourceType:  string(*rs.SourceType),
+			Enforcement: string(rs.Enforcement),
+			RuleTypes:   rulesetRuleTypes(rs),
+		})
+	}
+	return inherited
+}
+
+// rulesetRuleTypes lists the rule types configured in a ruleset, in the order
+// the API serializes them.
+func rulesetRuleTypes(rs *github.RepositoryRuleset) []string {
+	if rs.Rules == nil {
+		return nil
+	}
+	data, err := json.Marshal(rs.Rules)
+	if err != nil {
+		return nil
+	}
+	var rules []*github.RepositoryRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil
+	}
+	var types []string
+	for _, r := range rules {
+		types = append(types, string(r.Type))
+	}
+	return types
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// Repository role ID GitHub uses for the built-in admin role in bypass lists.
+const repositoryRoleAdminID = 5
+
+type UnmappedProtectionField struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+type ProtectionMigrationPreview struct {
+	Repo     string                     `json:"repo"`
+	Branch   string                     `json:"branch"`
+	Ruleset  *github.RepositoryRuleset  `json:"ruleset"`
+	Unmapped []*UnmappedProtectionField `json:"unmapped"`
+}
+
+type ProtectionMigrationOptions struct {
+	RulesetName   string `json:"ruleset_name"`
+	Activate      bool   `json:"activate"`
+	RemoveClassic bool   `json:"remove_classic"`
+}
+
+type ProtectionMigrationResult struct {
+	Repo           string                     `json:"repo"`
+	Branch         string                     `json:"branch"`
+	RulesetID      int64                      `json:"ruleset_id"`
+	Enforcement    string                     `json:"enforcement"`
+	ClassicRemoved bool                       `json:"classic_removed"`
+	Unmapped       []*UnmappedProtectionField `json:"unmapped"`
+	Error          string                     `json:"error"`
+}
+
+// PreviewProtectionMigration translates a branch's classic protection into
+// an equivalent ruleset without changing anything. An empty branch uses the
+// repository's default branch.
+func (ghs *GitHubService) PreviewProtectionMigration(owner, repo, branch string) (*ProtectionMigrationPreview, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	return ghs.previewProtectionMigration(ctx, owner, repo, branch, "")
+}
+
+// MigrateProtectionToRuleset creates the translated ruleset in evaluate mode.
+// With Activate the ruleset is switched to active, and with RemoveClassic the
+// classic protection is then deleted.
+func (ghs *GitHubService) MigrateProtectionToRuleset(owner, repo, branch string, options *ProtectionMigrationOptions) (*ProtectionMigrationResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if options == nil {
+		options = &ProtectionMigrationOptions{}
+	}
+	if options.RemoveClassic && !options.Activate {
+		return nil, fmt.Errorf("classic protection can only be removed once the ruleset is active")
+	}
+	ctx := context.Background()
+
+	preview, err := ghs.previewProtectionMigration(ctx, owner, repo, branch, options.RulesetName)
+	if err != nil {
+		return nil, err
+	}
+	result := &ProtectionMigrationResult{
+		Repo:     preview.Repo,
+		Branch:   preview.Branch,
+		Unmapped: preview.Unmapped,
+	}
+
+	created, _, err := ghs.client.Repositories.CreateRuleset(ctx, owner, repo, *preview.Ruleset)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.RulesetID = created.GetID()
+	result.Enforcement = string(created.Enforcement)
+
+	if options.Activate {
+		err = ghs.activateMigratedRuleset(ctx, owner, repo, created.GetID(), preview.Branch, options.RemoveClassic, result)
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+	return result, nil
+}
+
+// ActivateMigratedRuleset switches a ruleset created by a migration from
+// evaluate to active, optionally removing the branch's classic protection.
+func (ghs *GitHubService) ActivateMigratedRuleset(owner, repo string, rulesetID int64, branch string, removeClassic bool) (*ProtectionMigrationResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	result := &ProtectionMigrationResult{
+		Repo:      owner + "/" + repo,
+		Branch:    branch,
+		RulesetID: rulesetID,
+	}
+	err := ghs.activateMigratedRuleset(ctx, owner, repo, rulesetID, branch, removeClassic, result)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+func (ghs *GitHubService) BulkMigrateProtectionToRulesets(fullRepos []string, branch string, options *ProtectionMigrationOptions) []*ProtectionMigrationResult {
+	var results []*ProtectionMigrationResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &ProtectionMigrationResult{Repo: fullRepo, Branch: branch, Error: "invalid repository name"})
+			continue
+		}
+		result, err := ghs.MigrateProtectionToRuleset(parts[0], parts[1], branch, options)
+		if err != nil {
+			result = &ProtectionMigrationResult{Repo: fullRepo, Branch: branch, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (ghs *GitHubService) activateMigratedRuleset(ctx context.Context, owner, repo string, rulesetID int64, branch string, removeClassic bool, result *ProtectionMigrationResult) error {
+	ruleset, _, err := ghs.client.Repositories.GetRuleset(ctx, owner, repo, rulesetID, false)
+	if err != nil {
+		return err
+	}
+	ruleset, err = sanitizeRuleset(ruleset)
+	if err != nil {
+		return err
+	}
+	ruleset.Enforcement = github.RulesetEnforcementActive
+	updated, _, err := ghs.client.Repositories.UpdateRuleset(ctx, owner, repo, rulesetID, *ruleset)
+	if err != nil {
+		return err
+	}
+	result.Enforcement = string(updated.Enforcement)
+
+	if removeClassic {
+		_, err = ghs.client.Repositories.RemoveBranchProtection(ctx, owner, repo, branch)
+		if err != nil {
+			return err
+		}
+		result.ClassicRemoved = true
+	}
+	return nil
+}
+
+func (ghs *GitHubService) previewProtectionMigration(ctx context.Context, owner, repo, branch, rulesetName string) (*ProtectionMigrationPreview, error) {
+	if branch == "" {
+		r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		branch = r.GetDefaultBranch()
+	}
+	protection, _, err := ghs.client.Repositories.GetBranchProtection(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if rulesetName == "" {
+		rulesetName = fmt.Sprintf("Migrated protection: %s", branch)
+	}
+	ruleset, unmapped := protectionToRuleset(rulesetName, branch, protection)
+	return &ProtectionMigrationPreview{
+		Repo:     owner + "/" + repo,
+		Branch:   branch,
+		Ruleset:  ruleset,
+		Unmapped: unmapped,
+	}, nil
+}
+
+// protectionToRuleset builds a branch ruleset in evaluate mode that enforces
+// the same rules as a classic protection, and lists the settings that have
+// no ruleset equivalent.
+func protectionToRuleset(name, branch string, p *github.Protection) (*github.RepositoryRuleset, []*UnmappedProtectionField) {
+	var unmapped []*UnmappedProtectionField
+	flag := func(field, reason string) {
+		unmapped = append(unmapped, &UnmappedProtectionField{Field: field, Reason: reason})
+	}
+
+	rules := &github.RepositoryRulesetRules{}
+
+	if rsc := p.GetRequiredStatusChecks(); rsc != nil {
+		params := &github.RequiredStatusChecksRuleParameters{
+			RequiredStatusChecks:             []*github.RuleStatusCheck{},
+			StrictRequiredStatusChecksPolicy: rsc.Strict,
+		}
+		if rsc.Checks != nil {
+			for _, c := range *rsc.Checks {
+				check := &github.RuleStatusCheck{Context: c.Context}
+				if c.AppID != nil && *c.AppID > 0 {
+					check.IntegrationID = c.AppID
+				}
+				params.RequiredStatusChecks = append(params.RequiredStatusChecks, check)
+			}
+		} else if rsc.Contexts != nil {
+			for _, c := range *rsc.Contexts {
+				params.RequiredStatusChecks = append(params.RequiredStatusChecks, &github.RuleStatusCheck{Context: c})
+			}
+		}
+		rules.RequiredStatusChecks = params
+	}
+
+	conversationResolution := p.RequiredConversationResolution != nil && p.RequiredConversationResolution.Enabled
+	if prr := p.GetRequiredPullRequestReviews(); prr != nil {
+		rules.PullRequest = &github.PullRequestRuleParameters{
+			AllowedMergeMethods: []github.PullRequestMergeMethod{
+				github.PullRequestMergeMethodMerge,
+				github.PullRequestMergeMethodSquash,
+				github.PullRequestMergeMethodRebase,
+			},
+			DismissStaleReviewsOnPush:      prr.DismissStaleReviews,
+			RequireCodeOwnerReview:         prr.RequireCodeOwnerReviews,
+			RequireLastPushApproval:        prr.RequireLastPushApproval,
+			RequiredApprovingReviewCount:   prr.RequiredApprovingReviewCount,
+			RequiredReviewThreadResolution: conversationResolution,
+		}
+		if dr := prr.DismissalRestrictions; dr != nil && len(dr.Users)+len(dr.Teams)+len(dr.Apps) > 0 {
+			flag("required_pull_request_reviews.dismissal_restrictions", "rulesets cannot restrict who may dismiss reviews")
+		}
+		if bp := prr.BypassPullRequestAllowances; bp != nil && len(bp.Users)+len(bp.Teams)+len(bp.Apps) > 0 {
+			flag("required_pull_request_reviews.bypass_pull_request_allowances", "ruleset bypass actors bypass every rule, not only pull request requirements; add them manually if intended")
+		}
+	} else if conversationResolution {
+		flag("required_conversation_resolution", "rulesets only support conversation resolution as part of a pull request rule")
+	}
+
+	if p.RequireLinearHistory != nil && p.RequireLinearHistory.Enabled {
+		rules.RequiredLinearHistory = &github.EmptyRuleParameters{}
+	}
+	if p.AllowForcePushes == nil || !p.AllowForcePushes.Enabled {
+		rules.NonFastForward = &github.EmptyRuleParameters{}
+	}
+	if p.AllowDeletions == nil || !p.AllowDeletions.Enabled {
+		rules.Deletion = &github.EmptyRuleParameters{}
+	}
+	if p.GetRequiredSignatures().GetEnabled() {
+		rules.RequiredSignatures = &github.EmptyRuleParameters{}
+	}
+	if p.GetLockBranch().GetEnabled() {
+		rules.Update = &github.UpdateRuleParameters{
+			UpdateAllowsFetchAndMerge: p.GetAllowForkSyncing().GetEnabled(),
+		}
+	}
+	if p.GetBlockCreations().GetEnabled() {
+		rules.Creation = &github.EmptyRuleParameters{}
+	}
+	if r := p.GetRestrictions(); r != nil {
+		flag("restrictions", "rulesets have no per-user push allow list; use a restrict-updates rule with bypass actors instead")
+	}
+
+	var bypass []*github.BypassActor
+	if p.EnforceAdmins == nil || !p.EnforceAdmins.Enabled {
+		bypass = append(bypass, &github.BypassActor{
+			ActorID:    github.Ptr(int64(repositoryRoleAdminID)),
+			ActorType:  github.Ptr(github.BypassActorTypeRepositoryRole),
+			BypassMode: github.Ptr(github.BypassModeAlways),
+		})
+	}
+
+	return &github.RepositoryRuleset{
+		Name:         name,
+		Target:       github.Ptr(github.RulesetTargetBranch),
+		Enforcement:  github.RulesetEnforcementEvaluate,
+		BypassActors: bypass,
+		Conditions: &github.RepositoryRulesetConditions{
+			RefName: &github.RepositoryRulesetRefConditionParameters{
+				Include: []string{"refs/heads/" + branch},
+				Exclude: []string{},
+			},
+		},
+		Rules: rules,
+	}, unmapped
+}
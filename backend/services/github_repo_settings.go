@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// GitHubRepoSettings holds the repository settings that can be standardized
+// in bulk. Nil fields are left unchanged on update.
+type GitHubRepoSettings struct {
+	AllowMergeCommit         *bool   `json:"allow_merge_commit"`
+	AllowSquashMerge         *bool   `json:"allow_squash_merge"`
+	AllowRebaseMerge         *bool   `json:"allow_rebase_merge"`
+	AllowAutoMerge           *bool   `json:"allow_auto_merge"`
+	AllowUpdateBranch        *bool   `json:"allow_update_branch"`
+	DeleteBranchOnMerge      *bool   `json:"delete_branch_on_merge"`
+	SquashMergeCommitTitle   *string `json:"squash_merge_commit_title"`   // "PR_TITLE" or "COMMIT_OR_PR_TITLE"
+	SquashMergeCommitMessage *string `json:"squash_merge_commit_message"` // "PR_BODY", "COMMIT_MESSAGES" or "BLANK"
+	MergeCommitTitle         *string `json:"merge_commit_title"`          // "PR_TITLE" or "MERGE_MESSAGE"
+	MergeCommitMessage       *string `json:"merge_commit_message"`        // "PR_BODY", "PR_TITLE" or "BLANK"
+	HasIssues                *bool   `json:"has_issues"`
+	HasWiki                  *bool   `json:"has_wiki"`
+	HasProjects              *bool   `json:"has_projects"`
+	HasDiscussions           *bool   `json:"has_discussions"`
+}
+
+type RepoSettingChange struct {
+	Setting  string `json:"setting"`
+	OldValue any    `json:"old_value"`
+	NewValue any    `json:"new_value"`
+}
+
+type RepoSettingsResult struct {
+	Repo    string               `json:"repo"`
+	Changes []*RepoSettingChange `json:"changes"`
+	Applied bool                 `json:"applied"`
+	Error   string               `json:"error"`
+}
+
+func repoSettingsFromRepository(repo *github.Repository) *GitHubRepoSettings {
+	return &GitHubRepoSettings{
+		AllowMergeCommit:         github.Ptr(repo.GetAllowMergeCommit()),
+		AllowSquashMerge:         github.Ptr(repo.GetAllowSquashMerge()),
+		AllowRebaseMerge:         github.Ptr(repo.GetAllowRebaseMerge()),
+		AllowAutoMerge:           github.Ptr(repo.GetAllowAutoMerge()),
+		AllowUpdateBranch:        github.Ptr(repo.GetAllowUpdateBranch()),
+		DeleteBranchOnMerge:      github.Ptr(repo.GetDeleteBranchOnMerge()),
+		SquashMergeCommitTitle:   github.Ptr(repo.GetSquashMergeCommitTitle()),
+		SquashMergeCommitMessage: github.Ptr(repo.GetSquashMergeCommitMessage()),
+		MergeCommitTitle:         github.Ptr(repo.GetMergeCommitTitle()),
+		MergeCommitMessage:       github.Ptr(repo.GetMergeCommitMessage()),
+		HasIssues:                github.Ptr(repo.GetHasIssues()),
+		HasWiki:                  github.Ptr(repo.GetHasWiki()),
+		HasProjects:              github.Ptr(repo.GetHasProjects()),
+		HasDiscussions:           github.Ptr(repo.GetHasDiscussions()),
+	}
+}
+
+func (s *GitHubRepoSettings) toRepository() *github.Repository {
+	return &github.Repository{
+		AllowMergeCommit:         s.AllowMergeCommit,
+		AllowSquashMerge:         s.AllowSquashMerge,
+		AllowRebaseMerge:         s.AllowRebaseMerge,
+		AllowAutoMerge:           s.AllowAutoMerge,
+		AllowUpdateBranch:        s.AllowUpdateBranch,
+		DeleteBranchOnMerge:      s.DeleteBranchOnMerge,
+		SquashMergeCommitTitle:   s.SquashMergeCommitTitle,
+		SquashMergeCommitMessage: s.SquashMergeCommitMessage,
+		MergeCommitTitle:         s.MergeCommitTitle,
+		MergeCommitMessage:       s.MergeCommitMessage,
+		HasIssues:                s.HasIssues,
+		HasWiki:                  s.HasWiki,
+		HasProjects:              s.HasProjects,
+		HasDiscussions:           s.HasDiscussions,
+	}
+}
+
+// diffRepoSettings lists the requested settings that differ from current.
+func diffRepoSettings(current, requested *GitHubRepoSettings) []*RepoSettingChange {
+	var changes []*RepoSettingChange
+	diffBool := func(name string, cur, req *bool) {
+		if req != nil && (cur == nil || *cur != *req) {
+			changes = append(changes, &RepoSettingChange{Setting: name, OldValue: cur, NewValue: *req})
+		}
+	}
+	diffString := func(name string, cur, req *string) {
+		if req != nil && (cur == nil || *cur != *req) {
+			changes = append(changes, &RepoSettingChange{Setting: name, OldValue: cur, NewValue: *req})
+		}
+	}
+	diffBool("allow_merge_commit", current.AllowMergeCommit, requested.AllowMergeCommit)
+	diffBool("allow_squash_merge", current.AllowSquashMerge, requested.AllowSquashMerge)
+	diffBool("allow_rebase_merge", current.AllowRebaseMerge, requested.AllowRebaseMerge)
+	diffBool("allow_auto_merge", current.AllowAutoMerge, requested.AllowAutoMerge)
+	diffBool("allow_update_branch", current.AllowUpdateBranch, requested.AllowUpdateBranch)
+	diffBool("delete_branch_on_merge", current.DeleteBranchOnMerge, requested.DeleteBranchOnMerge)
+	diffString("squash_merge_commit_title", current.SquashMergeCommitTitle, requested.SquashMergeCommitTitle)
+	diffString("squash_merge_commit_message", current.SquashMergeCommitMessage, requested.SquashMergeCommitMessage)
+	diffString("merge_commit_title", current.MergeCommitTitle, requested.MergeCommitTitle)
+	diffString("merge_commit_message", current.MergeCommitMessage, requested.MergeCommitMessage)
+	diffBool("has_issues", current.HasIssues, requested.HasIssues)
+	diffBool("has_wiki", current.HasWiki, requested.HasWiki)
+	diffBool("has_projects", current.HasProjects, requested.HasProjects)
+	diffBool("has_discussions", current.HasDiscussions, requested.HasDiscussions)
+	return changes
+}
+
+// validateMergeMethods rejects settings that would leave a repository with no
+// allowed merge method once applied on top of current.
+func validateMergeMethods(current, requested *GitHubRepoSettings) error {
+	pick := func(req, cur *bool) bool {
+		if req != nil {
+			return *req
+		}
+		return cur != nil && *cur
+	}
+	if !pick(requested.AllowMergeCommit, current.AllowMergeCommit) &&
+		!pick(requested.AllowSquashMerge, current.AllowSquashMerge) &&
+		!pick(requested.AllowRebaseMerge, current.AllowRebaseMerge) {
+		return fmt.Errorf("at least one merge method must be allowed")
+	}
+	return nil
+}
+
+func (ghs *GitHubService) UpdateRepoSettings(owner, repo string, settings *GitHubRepoSettings) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	result := ghs.updateRepoSettings(ctx, owner, repo, settings, false)
+	if result.Error != "" {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// BulkUpdateRepoSettings applies the same settings to many repositories. With
+// dryRun nothing is written and each result lists the changes that would be
+// made.
+func (ghs *GitHubService) BulkUpdateRepoSettings(fullRepos []string, settings *GitHubRepoSettings, dryRun bool) ([]*RepoSettingsResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*RepoSettingsResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &RepoSettingsResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		results = append(results, ghs.updateRepoSettings(ctx, parts[0], parts[1], settings, dryRun))
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) updateRepoSettings(ctx context.Context, owner, repo string, settings *GitHubRepoSettings, dryRun bool) *RepoSettingsResult {
+	result := &RepoSettingsResult{Repo: owner + "/" + repo}
+	if settings == nil {
+		result.Error = "no settings given"
+		return result
+	}
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	current := repoSettingsFromRepository(r)
+	if err := validateMergeMethods(current, settings); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Changes = diffRepoSettings(current, settings)
+	if dryRun || len(result.Changes) == 0 {
+		return result
+	}
+	_, _, err = ghs.client.Repositories.Edit(ctx, owner, repo, settings.toRepository())
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Applied = true
+	return result
+}
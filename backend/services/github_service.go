@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -18,6 +19,9 @@ type GitHubService struct {
 	ghCtx      context.Context
 	token      string
 	Status     GitHubServiceStatus
+
+	matrixMu     sync.Mutex
+	teamMatrices map[string]*TeamRepoMatrix
 }
 
 type GitHubFetchErrorEvent struct {
@@ -31,6 +35,8 @@ type GitHubTeam struct {
 	Slug         string `json:"slug"`
 	Url          string `json:"url"`
 	MembersCount int    `json:"members_count"`
+	ParentSlug   string `json:"parent_slug"`
+	ChildCount   int    `json:"child_count"`
 }
 
 type GitHubServiceStatus struct {
@@ -55,9 +61,11 @@ type GitHubRepo struct {
 }
 
 type GitHubRepoTeam struct {
-	Name       string `json:"name"`
-	Slug       string `json:"slug"`
-	Permission string `json:"permission"`
+	Name          string `json:"name"`
+	Slug          string `json:"slug"`
+	Permission    string `json:"permission"`
+	Inherited     bool   `json:"inherited"`
+	InheritedFrom string `json:"inherited_from"` // Slug of the ancestor team holding the grant
 }
 
 type GitHubBranchProtectionDetail struct {
@@ -75,8 +83,12 @@ type GitHubRepoDetailed struct {
 	BranchesCount    int                             `json:"branches_count"`
 	CustomProperties []*github.CustomPropertyValue   `json:"custom_properties"`
 	Teams            []*GitHubRepoTeam               `json:"teams"`
+	Collaborators    []*GitHubCollaborator           `json:"collaborators"`
 	Protection       []*GitHubBranchProtectionDetail `json:"protection"`
 	Rulesets         []*github.RepositoryRuleset     `json:"rulesets"`
+	InheritedRules   []*GitHubInheritedRuleset       `json:"inherited_rules"`
+	Settings         *GitHubRepoSettings             `json:"settings"`
+	ActionsSettings  *GitHubActionsSettings          `json:"actions_settings"`
 }
 
 type GitHubReposUpdatedEvent struct {
@@ -182,6 +194,7 @@ func (ghs *GitHubService) Logout() {
 	ghs.token = ""
 	ghs.client = nil
 	ghs.Status = GitHubServiceStatus{}
+	ghs.clearTeamRepoMatrices()
 	cfg, _ := LoadConfig()
 	cfg.GitHubToken = ""
 	cfg.SelectedOrg = ""
@@ -292,6 +305,7 @@ func (ghs *GitHubService) fetchTeamList(org string) {
 					Slug:         t.GetSlug(),
 					Url:          t.GetHTMLURL(),
 					MembersCount: mCount,
+					ParentSlug:   t.GetParent().GetSlug(),
 				}
 			}(i, team)
 		}
@@ -302,6 +316,7 @@ func (ghs *GitHubService) fetchTeamList(org string) {
 		}
 		opt.Page = resp.NextPage
 	}
+	countTeamChildren(allTeams)
 
 	app := application.Get()
 	if app != nil {
@@ -322,76 +337,79 @@ func (ghs *GitHubService) fetchRepoList(org string) {
 		ctx = context.Background()
 	}
 
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		fmt.Printf("Error fetching repos for %s: %v\n", org, err)
+		ghs.emitFetchError(org, "repos", err.Error())
+		return
+	}
+
+	var allRepos []*GitHubRepo
+	for _, repo := range repos {
+		canManage := false
+		if p := repo.GetPermissions(); p != nil {
+			canManage = p["admin"] || p["maintain"] || p["push"]
+		}
+		allRepos = append(allRepos, &GitHubRepo{
+			Name:          repo.GetName(),
+			FullName:      repo.GetFullName(),
+			Url:           repo.GetHTMLURL(),
+			Topics:        repo.Topics,
+			Archived:      repo.GetArchived(),
+			Public:        !repo.GetPrivate(),
+			Visibility:    repo.GetVisibility(),
+			IsFork:        repo.GetFork(),
+			DefaultBranch: repo.GetDefaultBranch(),
+			CanManage:     canManage,
+		})
+	}
+	fmt.Printf("Fetched %d repos for %s\n", len(allRepos), org)
+
+	app := application.Get()
+	if app != nil {
+		app.Event.Emit("github:repos:updated", &GitHubReposUpdatedEvent{
+			Org:   org,
+			Repos: allRepos,
+		})
+	}
+}
+
+// listOwnerRepos returns every repository of an organization, or the
+// repositories owned by the authenticated user when owner is their login.
+func (ghs *GitHubService) listOwnerRepos(ctx context.Context, owner string) ([]*github.Repository, error) {
+	user, _, err := ghs.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
 	opt := &github.RepositoryListByOrgOptions{
 		Sort:        "full_name",
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-	userOpt := &github.RepositoryListOptions{
+	userOpt := &github.RepositoryListByAuthenticatedUserOptions{
 		Affiliation: "owner",
 		Sort:        "full_name",
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
-
-	var allRepos []*GitHubRepo
-
-	user, _, err := ghs.client.Users.Get(ctx, "")
-	if err != nil {
-		fmt.Printf("Error getting user info: %v\n", err)
-		ghs.emitFetchError(org, "repos", err.Error())
-		return
-	}
-
+	var all []*github.Repository
 	for {
 		var repos []*github.Repository
 		var resp *github.Response
-		var err error
-
-		if user.GetLogin() == org {
-			repos, resp, err = ghs.client.Repositories.ListByAuthenticatedUser(ctx, (*github.RepositoryListByAuthenticatedUserOptions)(userOpt))
+		if user.GetLogin() == owner {
+			repos, resp, err = ghs.client.Repositories.ListByAuthenticatedUser(ctx, userOpt)
 		} else {
-			repos, resp, err = ghs.client.Repositories.ListByOrg(ctx, org, opt)
+			repos, resp, err = ghs.client.Repositories.ListByOrg(ctx, owner, opt)
 		}
-
 		if err != nil {
-			fmt.Printf("Error fetching repos for %s: %v\n", org, err)
-			ghs.emitFetchError(org, "repos", err.Error())
-			return
-		}
-		for _, repo := range repos {
-			canManage := false
-			if p := repo.GetPermissions(); p != nil {
-				canManage = p["admin"] || p["maintain"] || p["push"]
-			}
-			allRepos = append(allRepos, &GitHubRepo{
-				Name:          repo.GetName(),
-				FullName:      repo.GetFullName(),
-				Url:           repo.GetHTMLURL(),
-				Topics:        repo.Topics,
-				Archived:      repo.GetArchived(),
-				Public:        !repo.GetPrivate(),
-				Visibility:    repo.GetVisibility(),
-				IsFork:        repo.GetFork(),
-				DefaultBranch: repo.GetDefaultBranch(),
-				CanManage:     canManage,
-			})
+			return nil, err
 		}
-
+		all = append(all, repos...)
 		if resp.NextPage == 0 {
 			break
-		} else {
-			opt.Page = resp.NextPage
-			userOpt.Page = resp.NextPage
 		}
+		opt.Page = resp.NextPage
+		userOpt.Page = resp.NextPage
 	}
-	fmt.Printf("Fetched %d repos for %s\n", len(allRepos), org)
-
-	app := application.Get()
-	if app != nil {
-		app.Event.Emit("github:repos:updated", &GitHubReposUpdatedEvent{
-			Org:   org,
-			Repos: allRepos,
-		})
-	}
+	return all, nil
 }
 
 func (ghs *GitHubService) fetchAllForAllOrgs() {
@@ -473,34 +491,19 @@ func (ghs *GitHubService) GetRepoDetails(owner, repoName string) (*GitHubRepoDet
 		Stars:       repo.GetStargazersCount(),
 		Watching:    repo.GetWatchersCount(),
 		ForksCount:  repo.GetForksCount(),
+		Settings:    repoSettingsFromRepository(repo),
 	}
 
 	// 2. Get Open PRs count
-	query := fmt.Sprintf("repo:%s/%s type:pr state:open", owner, repoName)
-	searchRes, _, err := ghs.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	openPRs, err := ghs.countOpenPRs(ctx, owner, repoName)
 	if err == nil {
-		detailed.OpenPRs = searchRes.GetTotal()
+		detailed.OpenPRs = openPRs
 	}
 
 	// 3. Get Branches count
-	branches, resp, err := ghs.client.Repositories.ListBranches(ctx, owner, repoName, &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	branches, err := ghs.listBranches(ctx, owner, repoName)
 	if err == nil {
-		if resp.LastPage > 0 {
-			// This is an estimation if more than one page, but ListBranches doesn't give total count.
-			// To be accurate we'd need to follow pages, but let's just use what we have or count.
-			count := len(branches)
-			for resp.NextPage > 0 {
-				var more []*github.Branch
-				more, resp, err = ghs.client.Repositories.ListBranches(ctx, owner, repoName, &github.BranchListOptions{ListOptions: github.ListOptions{Page: resp.NextPage, PerPage: 100}})
-				if err != nil {
-					break
-				}
-				count += len(more)
-			}
-			detailed.BranchesCount = count
-		} else {
-			detailed.BranchesCount = len(branches)
-		}
+		detailed.BranchesCount = len(branches)
 	}
 
 	// 4. Custom Properties
@@ -521,6 +524,12 @@ func (ghs *GitHubService) GetRepoDetails(owner, repoName string) (*GitHubRepoDet
 		}
 	}
 
+	// 5b. Direct and outside collaborators
+	collaborators, err := ghs.listRepoCollaborators(ctx, owner, repoName)
+	if err == nil {
+		detailed.Collaborators = collaborators
+	}
+
 	// 6. Branch Protection
 	// We use the branches we already fetched
 	for _, b := range branches {
@@ -536,22 +545,97 @@ func (ghs *GitHubService) GetRepoDetails(owner, repoName string) (*GitHubRepoDet
 	}
 
 	// 7. Rulesets
-	rulesets, _, err := ghs.client.Repositories.GetAllRulesets(ctx, owner, repoName, nil)
+	rulesets, err := ghs.fetchRulesets(ctx, owner, repoName, true)
 	if err == nil {
-		// Fetch full details for each ruleset to get the rules
-		for _, rs := range rulesets {
-			fullRs, _, err := ghs.client.Repositories.GetRuleset(ctx, owner, repoName, rs.GetID(), false)
-			if err == nil {
-				detailed.Rulesets = append(detailed.Rulesets, fullRs)
-			} else {
-				detailed.Rulesets = append(detailed.Rulesets, rs)
-			}
-		}
+		detailed.Rulesets = rulesets
+		detailed.InheritedRules = inheritedRulesets(rulesets)
+	}
+
+	// 8. Actions settings
+	actionsSettings, err := ghs.repoActionsSettings(ctx, owner, repoName)
+	if err == nil {
+		detailed.ActionsSettings = actionsSettings
 	}
 
 	return detailed, nil
 }
 
+func (ghs *GitHubService) countOpenPRs(ctx context.Context, owner, repoName string) (int, error) {
+	query := fmt.Sprintf("repo:%s/%s type:pr state:open", owner, repoName)
+	searchRes, _, err := ghs.client.Search.Issues(ctx, query, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}})
+	if err != nil {
+		return 0, err
+	}
+	return searchRes.GetTotal(), nil
+}
+
+// fetchRulesets lists the rulesets that apply to a repository, optionally
+// including those inherited from the organization, with their full rules.
+func (ghs *GitHubService) fetchRulesets(ctx context.Context, owner, repoName string, includeParents bool) ([]*github.RepositoryRuleset, error) {
+	opt := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(includeParents),
+		ListOptions:     github.ListOptions{PerPage: 100},
+	}
+	var rulesets []*github.RepositoryRuleset
+	for {
+		page, resp, err := ghs.client.Repositories.GetAllRulesets(ctx, owner, repoName, opt)
+		if err != nil {
+			return nil, err
+		}
+		rulesets = append(rulesets, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	var full []*github.RepositoryRuleset
+	// Fetch full details for each ruleset to get the rules
+	for _, rs := range rulesets {
+		fullRs, _, err := ghs.client.Repositories.GetRuleset(ctx, owner, repoName, rs.GetID(), includeParents)
+		if err == nil {
+			full = append(full, fullRs)
+		} else {
+			full = append(full, rs)
+		}
+	}
+	return full, nil
+}
+
+// fetchBranchProtections returns the classic protection of every protected
+// branch in a repository. Branches protected only by rulesets are skipped.
+func (ghs *GitHubService) fetchBranchProtections(ctx context.Context, owner, repoName string) ([]*GitHubBranchProtectionDetail, error) {
+	opt := &github.BranchListOptions{
+		Protected:   github.Ptr(true),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var protections []*GitHubBranchProtectionDetail
+	for {
+		branches, resp, err := ghs.client.Repositories.ListBranches(ctx, owner, repoName, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range branches {
+			protection, _, err := ghs.client.Repositories.GetBranchProtection(ctx, owner, repoName, b.GetName())
+			if errors.Is(err, github.ErrBranchNotProtected) {
+				// Protected only by a ruleset.
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			protections = append(protections, &GitHubBranchProtectionDetail{
+				BranchName: b.GetName(),
+				Protection: protection,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return protections, nil
+}
+
 func (ghs *GitHubService) UpdateRepoTopics(owner, repo string, topics []string, mode string) ([]string, error) {
 	if ghs.client == nil {
 		return nil, fmt.Errorf("not connected")
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type GitHubTeamNode struct {
+	GitHubTeam
+	Children []*GitHubTeamNode `json:"children"`
+}
+
+// GetTeamTree returns the teams of an org arranged by parent team. Root
+// teams and children are sorted by name.
+func (ghs *GitHubService) GetTeamTree(org string) ([]*GitHubTeamNode, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	teams, err := ghs.listOrgTeams(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*GitHubTeamNode)
+	for _, t := range teams {
+		nodes[t.GetSlug()] = &GitHubTeamNode{GitHubTeam: GitHubTeam{
+			Name:         t.GetName(),
+			Slug:         t.GetSlug(),
+			Url:          t.GetHTMLURL(),
+			MembersCount: t.GetMembersCount(),
+			ParentSlug:   t.GetParent().GetSlug(),
+		}}
+	}
+	var roots []*GitHubTeamNode
+	for _, t := range teams {
+		node := nodes[t.GetSlug()]
+		parent, ok := nodes[node.ParentSlug]
+		if !ok {
+			// Parents hidden from the authenticated user are treated as roots.
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+		parent.ChildCount++
+	}
+	sortTeamNodes(roots)
+	return roots, nil
+}
+
+// SetTeamParent moves a team under parentSlug, or makes it a root team when
+// parentSlug is empty. A team cannot be moved under one of its own
+// descendants.
+func (ghs *GitHubService) SetTeamParent(org, teamSlug, parentSlug string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	team, _, err := ghs.client.Teams.GetTeamBySlug(ctx, org, teamSlug)
+	if err != nil {
+		return err
+	}
+	edit := github.NewTeam{Name: team.GetName()}
+	if parentSlug != "" {
+		if parentSlug == teamSlug {
+			return fmt.Errorf("a team cannot be its own parent")
+		}
+		descendants, err := ghs.teamDescendants(ctx, org, teamSlug)
+		if err != nil {
+			return err
+		}
+		if _, ok := descendants[parentSlug]; ok {
+			return fmt.Errorf("%s is a child of %s", parentSlug, teamSlug)
+		}
+		parent, _, err := ghs.client.Teams.GetTeamBySlug(ctx, org, parentSlug)
+		if err != nil {
+			return err
+		}
+		edit.ParentTeamID = github.Ptr(parent.GetID())
+	}
+	_, _, err = ghs.client.Teams.EditTeamBySlug(ctx, org, teamSlug, edit, parentSlug == "")
+	if err != nil {
+		return err
+	}
+	ghs.RefreshRepoList(org)
+	return nil
+}
+
+// GetRepoInheritedTeams lists the teams that reach a repository through a
+// parent team's grant. It's fetched separately from GetRepoDetails since it
+// needs the org's whole team hierarchy.
+func (ghs *GitHubService) GetRepoInheritedTeams(owner, repoName string) ([]*GitHubRepoTeam, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	teams, err := ghs.listRepoTeams(ctx, owner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	return ghs.inheritedRepoTeams(ctx, owner, teams)
+}
+
+// inheritedRepoTeams lists the descendants of the teams granted access to a
+// repository. Child teams inherit their ancestors' repository access.
+func (ghs *GitHubService) inheritedRepoTeams(ctx context.Context, org string, teams []*github.Team) ([]*GitHubRepoTeam, error) {
+	if len(teams) == 0 {
+		return nil, nil
+	}
+	orgTeams, err := ghs.listOrgTeams(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[string][]*github.Team)
+	for _, t := range orgTeams {
+		if parent := t.GetParent().GetSlug(); parent != "" {
+			children[parent] = append(children[parent], t)
+		}
+	}
+
+	var inherited []*GitHubRepoTeam
+	for _, t := range teams {
+		seen := make(map[string]bool)
+		queue := []string{t.GetSlug()}
+		for len(queue) > 0 {
+			for _, c := range children[queue[0]] {
+				if seen[c.GetSlug()] {
+					continue
+				}
+				seen[c.GetSlug()] = true
+				queue = append(queue, c.GetSlug())
+				inherited = append(inherited, &GitHubRepoTeam{
+					Name:          c.GetName(),
+					Slug:          c.GetSlug(),
+					Permission:    t.GetPermission(),
+					Inherited:     true,
+					InheritedFrom: t.GetSlug(),
+				})
+			}
+			queue = queue[1:]
+		}
+	}
+	sort.Slice(inherited, func(i, j int) bool { return inherited[i].Slug < inherited[j].Slug })
+	return inherited, nil
+}
+
+// teamDescendants returns every team below slug, keyed by slug.
+func (ghs *GitHubService) teamDescendants(ctx context.Context, org, slug string) (map[string]*github.Team, error) {
+	descendants := make(map[string]*github.Team)
+	queue := []string{slug}
+	for len(queue) > 0 {
+		children, err := ghs.listChildTeams(ctx, org, queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, c := range children {
+			if _, seen := descendants[c.GetSlug()]; seen {
+				continue
+			}
+			descendants[c.GetSlug()] = c
+			queue = append(queue, c.GetSlug())
+		}
+	}
+	return descendants, nil
+}
+
+// countTeamChildren sets ChildCount from the ParentSlug of the other teams.
+func countTeamChildren(teams []*GitHubTeam) {
+	counts := make(map[string]int)
+	for _, t := range teams {
+		if t != nil && t.ParentSlug != "" {
+			counts[t.ParentSlug]++
+		}
+	}
+	for _, t := range teams {
+		if t != nil {
+			t.ChildCount = counts[t.Slug]
+		}
+	}
+}
+
+func sortTeamNodes(nodes []*GitHubTeamNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, n := range nodes {
+		sortTeamNodes(n.Children)
+	}
+}
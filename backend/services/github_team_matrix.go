@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+// TeamRepoMatrix holds the permission of every team on every repository of an
+// org. Cells maps team slug to full repository name to permission; missing
+// entries mean the team has no access. A cached matrix is shared with the
+// frontend and must not be modified once stored.
+type TeamRepoMatrix struct {
+	Org       string                       `json:"org"`
+	Teams     []*GitHubTeam                `json:"teams"`
+	Repos     []string                     `json:"repos"`
+	Cells     map[string]map[string]string `json:"cells"`
+	Errors    map[string]string            `json:"errors"` // Team slug -> error from listing its repositories
+	UpdatedAt time.Time                    `json:"updated_at"`
+}
+
+type TeamRepoMatrixUpdatedEvent struct {
+	Org    string          `json:"org"`
+	Matrix *TeamRepoMatrix `json:"matrix"`
+}
+
+// GetTeamRepoMatrix returns the cached teams × repos matrix of an org,
+// building it on first use or when refresh is set.
+func (ghs *GitHubService) GetTeamRepoMatrix(org string, refresh bool) (*TeamRepoMatrix, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !refresh {
+		ghs.matrixMu.Lock()
+		cached := ghs.teamMatrices[org]
+		ghs.matrixMu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+	}
+	return ghs.refreshTeamRepoMatrix(context.Background(), org, nil, true)
+}
+
+// RefreshTeamRepoMatrix updates the cached matrix without rebuilding it:
+// the given teams are re-listed, teams added to the org since the last
+// refresh are fetched, and deleted teams are dropped. With no cached matrix
+// it is built in full.
+func (ghs *GitHubService) RefreshTeamRepoMatrix(org string, teamSlugs []string) (*TeamRepoMatrix, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.refreshTeamRepoMatrix(context.Background(), org, teamSlugs, false)
+}
+
+// SetTeamRepoMatrixCell changes the permission of a team on a repository.
+// An empty permission or "none" removes the team's access. The cached matrix
+// is never changed in place since it may be serialized concurrently; a copy
+// with the new cell replaces it.
+func (ghs *GitHubService) SetTeamRepoMatrixCell(org, teamSlug, fullRepo, permission string) (*TeamRepoMatrix, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	parts := strings.Split(fullRepo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository name %q", fullRepo)
+	}
+	remove := permission == "" || permission == "none"
+	apiPermission := ""
+	if !remove {
+		var err error
+		if apiPermission, err = teamRepoAPIPermission(permission); err != nil {
+			return nil, err
+		}
+	}
+	if err := ghs.UpdateRepoTeam(parts[0], parts[1], org, teamSlug, apiPermission, remove); err != nil {
+		return nil, err
+	}
+
+	ghs.matrixMu.Lock()
+	var updated *TeamRepoMatrix
+	if current := ghs.teamMatrices[org]; current != nil {
+		clone := *current
+		clone.Cells = maps.Clone(current.Cells)
+		cells := maps.Clone(current.Cells[teamSlug])
+		if cells == nil {
+			cells = make(map[string]string)
+		}
+		if remove {
+			delete(cells, fullRepo)
+		} else {
+			cells[fullRepo] = normalizePermission(apiPermission)
+		}
+		clone.Cells[teamSlug] = cells
+		clone.UpdatedAt = time.Now()
+		ghs.teamMatrices[org] = &clone
+		updated = &clone
+	}
+	ghs.matrixMu.Unlock()
+
+	if updated == nil {
+		return ghs.GetTeamRepoMatrix(org, false)
+	}
+	ghs.emitTeamRepoMatrix(org, updated)
+	return updated, nil
+}
+
+// teamRepoAPIPermission maps a matrix permission to the value the team
+// repository API accepts.
+func teamRepoAPIPermission(permission string) (string, error) {
+	switch strings.ToLower(permission) {
+	case "read", "pull":
+		return "pull", nil
+	case "write", "push":
+		return "push", nil
+	case "triage", "maintain", "admin":
+		return strings.ToLower(permission), nil
+	}
+	return "", fmt.Errorf("invalid permission %q", permission)
+}
+
+func (ghs *GitHubService) refreshTeamRepoMatrix(ctx context.Context, org string, teamSlugs []string, full bool) (*TeamRepoMatrix, error) {
+	teams, err := ghs.listOrgTeams(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+
+	ghs.matrixMu.Lock()
+	previous := ghs.teamMatrices[org]
+	ghs.matrixMu.Unlock()
+	if previous == nil {
+		full = true
+	}
+
+	matrix := &TeamRepoMatrix{
+		Org:       org,
+		Cells:     make(map[string]map[string]string),
+		Errors:    make(map[string]string),
+		UpdatedAt: time.Now(),
+	}
+	for _, r := range repos {
+		matrix.Repos = append(matrix.Repos, r.GetFullName())
+	}
+	sort.Strings(matrix.Repos)
+
+	var stale []*github.Team
+	for _, t := range teams {
+		slug := t.GetSlug()
+		matrix.Teams = append(matrix.Teams, &GitHubTeam{
+			Name:         t.GetName(),
+			Slug:         slug,
+			Url:          t.GetHTMLURL(),
+			MembersCount: t.GetMembersCount(),
+			ParentSlug:   t.GetParent().GetSlug(),
+		})
+		if !full && !slices.Contains(teamSlugs, slug) {
+			if cells, ok := previous.Cells[slug]; ok {
+				matrix.Cells[slug] = cells
+				continue
+			}
+		}
+		stale = append(stale, t)
+	}
+
+	countTeamChildren(matrix.Teams)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for _, t := range stale {
+		wg.Add(1)
+		go func(team *github.Team) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			teamRepos, err := ghs.listTeamRepos(ctx, org, team.GetSlug())
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				matrix.Errors[team.GetSlug()] = err.Error()
+				return
+			}
+			cells := make(map[string]string)
+			for _, r := range teamRepos {
+				cells[r.GetFullName()] = repositoryPermission(r)
+			}
+			matrix.Cells[team.GetSlug()] = cells
+		}(t)
+	}
+	wg.Wait()
+
+	ghs.matrixMu.Lock()
+	if ghs.teamMatrices == nil {
+		ghs.teamMatrices = make(map[string]*TeamRepoMatrix)
+	}
+	ghs.teamMatrices[org] = matrix
+	ghs.matrixMu.Unlock()
+
+	ghs.emitTeamRepoMatrix(org, matrix)
+	return matrix, nil
+}
+
+func (ghs *GitHubService) emitTeamRepoMatrix(org string, matrix *TeamRepoMatrix) {
+	app := application.Get()
+	if app != nil {
+		app.Event.Emit("github:team_matrix:updated", &TeamRepoMatrixUpdatedEvent{Org: org, Matrix: matrix})
+	}
+}
+
+func (ghs *GitHubService) clearTeamRepoMatrices() {
+	ghs.matrixMu.Lock()
+	ghs.teamMatrices = nil
+	ghs.matrixMu.Unlock()
+}
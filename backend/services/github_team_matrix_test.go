@@ -0,0 +1,26 @@
+package services
+
+import "testing"
+
+func TestTeamRepoAPIPermission(t *testing.T) {
+	tests := map[string]string{
+		"read":     "pull",
+		"pull":     "pull",
+		"write":    "push",
+		"push":     "push",
+		"triage":   "triage",
+		"maintain": "maintain",
+		"admin":    "admin",
+	}
+	for in, want := range tests {
+		got, err := teamRepoAPIPermission(in)
+		if err != nil || got != want {
+			t.Errorf("teamRepoAPIPermission(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"owner", "custom-ci", "none"} {
+		if _, err := teamRepoAPIPermission(in); err == nil {
+			t.Errorf("teamRepoAPIPermission(%q): expected an error", in)
+		}
+	}
+}
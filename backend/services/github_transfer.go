@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"github.com/wailsapp/wails/v3/pkg/application"
+)
+
+type TransferAnalysis struct {
+	Repo             string                        `json:"repo"`
+	NewOwner         string                        `json:"new_owner"`
+	NewName          string                        `json:"new_name"`
+	NameAvailable    bool                          `json:"name_available"`
+	TeamsLost        []*GitHubRepoTeam             `json:"teams_lost"`
+	PropertiesLost   []*github.CustomPropertyValue `json:"properties_lost"`
+	PropertiesInDest []string                      `json:"properties_in_dest"` // Lost properties that the destination org also defines
+	Unverified       []string                      `json:"unverified"`         // Checks that failed, so the lists above may be incomplete
+}
+
+type TransferOptions struct {
+	NewName           string            `json:"new_name"`
+	TeamMapping       map[string]string `json:"team_mapping"` // Source team slug -> destination team slug
+	ReapplyProperties bool              `json:"reapply_properties"`
+}
+
+type TransferResult struct {
+	Repo          string   `json:"repo"`
+	NewFullName   string   `json:"new_full_name"`
+	Success       bool     `json:"success"`
+	Warnings      []string `json:"warnings"`
+	Error         string   `json:"error"`
+	GroupsUpdated bool     `json:"groups_updated"`
+	FollowUps     []string `json:"follow_ups"` // Steps left to do by hand because the transfer hadn't completed yet
+}
+
+// transferPollAttempts and transferPollInterval bound how long TransferRepo
+// waits for an accepted transfer to show up at the destination.
+const (
+	transferPollAttempts = 15
+	transferPollInterval = 2 * time.Second
+)
+
+// CheckRepoNameAvailable reports whether owner has no repository called name.
+func (ghs *GitHubService) CheckRepoNameAvailable(owner, name string) (bool, error) {
+	if ghs.client == nil {
+		return false, fmt.Errorf("not connected")
+	}
+	return ghs.repoNameAvailable(context.Background(), owner, name)
+}
+
+// RenameRepo renames a repository within its owner and updates any repo
+// groups that reference it.
+func (ghs *GitHubService) RenameRepo(owner, repo, newName string) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	available, err := ghs.repoNameAvailable(ctx, owner, newName)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("%s/%s already exists", owner, newName)
+	}
+	_, _, err = ghs.client.Repositories.Edit(ctx, owner, repo, &github.Repository{Name: github.Ptr(newName)})
+	if err != nil {
+		return err
+	}
+	err = moveRepoInGroups(owner, owner+"/"+repo, owner, owner+"/"+newName)
+	ghs.RefreshRepoList(owner)
+	return err
+}
+
+// AnalyzeTransfer reports what a transfer to newOwner would lose: team
+// grants always stay behind, and custom property values only carry over when
+// re-applied in the destination org.
+func (ghs *GitHubService) AnalyzeTransfer(owner, repo, newOwner, newName string) (*TransferAnalysis, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	if newName == "" {
+		newName = repo
+	}
+	analysis := &TransferAnalysis{Repo: owner + "/" + repo, NewOwner: newOwner, NewName: newName}
+
+	available, err := ghs.repoNameAvailable(ctx, newOwner, newName)
+	if err != nil {
+		return nil, err
+	}
+	analysis.NameAvailable = available
+
+	teams, err := ghs.listRepoTeams(ctx, owner, repo)
+	if err != nil {
+		analysis.Unverified = append(analysis.Unverified, fmt.Sprintf("teams: %v", err))
+	} else {
+		for _, t := range teams {
+			analysis.TeamsLost = append(analysis.TeamsLost, &GitHubRepoTeam{
+				Name:       t.GetName(),
+				Slug:       t.GetSlug(),
+				Permission: t.GetPermission(),
+			})
+		}
+	}
+
+	props, _, err := ghs.client.Repositories.GetAllCustomPropertyValues(ctx, owner, repo)
+	if err != nil {
+		analysis.Unverified = append(analysis.Unverified, fmt.Sprintf("custom properties: %v", err))
+	} else {
+		var destNames []string
+		if defs, _, err := ghs.client.Organizations.GetAllCustomProperties(ctx, newOwner); err != nil {
+			analysis.Unverified = append(analysis.Unverified, fmt.Sprintf("custom properties of %s: %v", newOwner, err))
+		} else {
+			for _, d := range defs {
+				destNames = append(destNames, d.GetPropertyName())
+			}
+		}
+		for _, p := range props {
+			if p.Value == nil {
+				continue
+			}
+			analysis.PropertiesLost = append(analysis.PropertiesLost, p)
+			if slices.Contains(destNames, p.PropertyName) {
+				analysis.PropertiesInDest = append(analysis.PropertiesInDest, p.PropertyName)
+			}
+		}
+	}
+	return analysis, nil
+}
+
+// TransferRepo moves a repository to newOwner. Teams in TeamMapping are given
+// the same permission in the destination org, properties the destination
+// also defines are re-applied when requested, and repo groups are updated.
+// The transfer completes asynchronously, so those steps are returned as
+// follow-ups when the repository doesn't show up at the destination in time.
+func (ghs *GitHubService) TransferRepo(owner, repo, newOwner string, options *TransferOptions) (*TransferResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if options == nil {
+		options = &TransferOptions{}
+	}
+	ctx := context.Background()
+
+	analysis, err := ghs.AnalyzeTransfer(owner, repo, newOwner, options.NewName)
+	if err != nil {
+		return nil, err
+	}
+	result := &TransferResult{Repo: analysis.Repo, NewFullName: newOwner + "/" + analysis.NewName}
+	if !analysis.NameAvailable {
+		result.Error = fmt.Sprintf("%s already exists", result.NewFullName)
+		return result, nil
+	}
+
+	req := github.TransferRequest{NewOwner: newOwner}
+	if options.NewName != "" && options.NewName != repo {
+		req.NewName = github.Ptr(options.NewName)
+	}
+	_, _, err = ghs.client.Repositories.Transfer(ctx, owner, repo, req)
+	// The API answers 202 Accepted since the transfer completes asynchronously.
+	var accepted *github.AcceptedError
+	if err != nil && !errors.As(err, &accepted) {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Success = true
+
+	arrived, err := ghs.waitForRepo(ctx, newOwner, analysis.NewName)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("waiting for transfer: %v", err))
+	}
+
+	for _, t := range analysis.TeamsLost {
+		destSlug, ok := options.TeamMapping[t.Slug]
+		if !ok || destSlug == "" {
+			continue
+		}
+		if !arrived {
+			result.FollowUps = append(result.FollowUps, fmt.Sprintf("grant team %s %s access", destSlug, t.Permission))
+			continue
+		}
+		if err := ghs.UpdateRepoTeam(newOwner, analysis.NewName, newOwner, destSlug, t.Permission, false); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("team %s: %v", destSlug, err))
+		}
+	}
+
+	if options.ReapplyProperties && len(analysis.PropertiesInDest) > 0 {
+		if !arrived {
+			result.FollowUps = append(result.FollowUps, "re-apply custom properties "+strings.Join(analysis.PropertiesInDest, ", "))
+		} else {
+			props := make(map[string]interface{})
+			for _, p := range analysis.PropertiesLost {
+				if slices.Contains(analysis.PropertiesInDest, p.PropertyName) {
+					props[p.PropertyName] = p.Value
+				}
+			}
+			if err := ghs.UpdateRepoCustomProperties(newOwner, analysis.NewName, props); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("custom properties: %v", err))
+			}
+		}
+	}
+
+	if err := moveRepoInGroups(owner, analysis.Repo, newOwner, result.NewFullName); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("repo groups: %v", err))
+	} else {
+		result.GroupsUpdated = true
+	}
+
+	ghs.RefreshRepoList(owner)
+	ghs.RefreshRepoList(newOwner)
+	return result, nil
+}
+
+// waitForRepo polls until owner/name exists, reporting false when it still
+// doesn't after transferPollAttempts tries.
+func (ghs *GitHubService) waitForRepo(ctx context.Context, owner, name string) (bool, error) {
+	for i := 0; i < transferPollAttempts; i++ {
+		available, err := ghs.repoNameAvailable(ctx, owner, name)
+		if err != nil {
+			return false, err
+		}
+		if !available {
+			return true, nil
+		}
+		time.Sleep(transferPollInterval)
+	}
+	return false, nil
+}
+
+func (ghs *GitHubService) repoNameAvailable(ctx context.Context, owner, name string) (bool, error) {
+	_, resp, err := ghs.client.Repositories.Get(ctx, owner, name)
+	if err == nil {
+		return false, nil
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return true, nil
+	}
+	return false, err
+}
+
+// moveRepoInGroups replaces oldFullName with newFullName in every repo group
+// of oldOrg. When the repo moved to another org, it is placed in groups of
+// the same name there.
+func moveRepoInGroups(oldOrg, oldFullName, newOrg, newFullName string) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for group, repos := range cfg.RepoGroups[oldOrg] {
+		idx := slices.Index(repos, oldFullName)
+		if idx == -1 {
+			continue
+		}
+		changed = true
+		if oldOrg == newOrg {
+			repos[idx] = newFullName
+			continue
+		}
+		cfg.RepoGroups[oldOrg][group] = slices.Delete(repos, idx, idx+1)
+		if cfg.RepoGroups[newOrg] == nil {
+			cfg.RepoGroups[newOrg] = make(map[string][]string)
+		}
+		if !slices.Contains(cfg.RepoGroups[newOrg][group], newFullName) {
+			cfg.RepoGroups[newOrg][group] = append(cfg.RepoGroups[newOrg][group], newFullName)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	err = SaveConfig(cfg)
+	if err != nil {
+		return err
+	}
+	app := application.Get()
+	if app != nil {
+		app.Event.Emit("config:updated", cfg)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type UserTeamMembership struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	Role string `json:"role"` // "member" or "maintainer"
+}
+
+type UserRepoAccess struct {
+	Repo       string         `json:"repo"`
+	Permission string         `json:"permission"`
+	Grants     []*AccessGrant `json:"grants"`
+	Error      string         `json:"error"` // Set when direct access to the repository couldn't be checked
+}
+
+type UserPendingInvitation struct {
+	Kind       string    `json:"kind"` // "org" or "repo"
+	Repo       string    `json:"repo"`
+	ID         int64     `json:"id"`
+	Role       string    `json:"role"`
+	Inviter    string    `json:"inviter"`
+	CreatedAt  time.Time `json:"created_at"`
+	Permission string    `json:"permission"`
+}
+
+type UserAccessReport struct {
+	Org            string                   `json:"org"`
+	Login          string                   `json:"login"`
+	OrgRole        string                   `json:"org_role"` // "admin", "member", "outside" or "" when the user has no access
+	BasePermission string                   `json:"base_permission"`
+	Teams          []*UserTeamMembership    `json:"teams"`
+	Repos          []*UserRepoAccess        `json:"repos"`
+	Invitations    []*UserPendingInvitation `json:"invitations"`
+	Warnings       []string                 `json:"warnings"`
+}
+
+type RevokeAccessResult struct {
+	Repo       string `json:"repo"`
+	Kind       string `json:"kind"` // "collaborator", "invitation" or "lookup" when direct access couldn't be checked
+	Permission string `json:"permission"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error"`
+}
+
+// userRepoGrants holds what a single repository grants a user directly.
+type userRepoGrants struct {
+	repo       *github.Repository
+	permission string
+	invitation *github.RepositoryInvitation
+	err        error
+}
+
+// GetUserAccess lists the teams of a user in an org, every repository they
+// can reach through a team or a direct grant, and their pending invitations.
+// Repositories only reachable through the org base permission are not listed
+// individually; see BasePermission.
+func (ghs *GitHubService) GetUserAccess(org, login string) (*UserAccessReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	report := &UserAccessReport{Org: org, Login: login}
+
+	o, _, err := ghs.client.Organizations.Get(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	report.BasePermission = normalizePermission(o.GetDefaultRepoPermission())
+
+	membership, resp, err := ghs.client.Organizations.GetOrgMembership(ctx, login, org)
+	switch {
+	case err == nil && membership.GetState() == "active":
+		report.OrgRole = membership.GetRole()
+	case err == nil || (resp != nil && resp.StatusCode == http.StatusNotFound):
+		// Pending members and non-members can only hold direct grants.
+	default:
+		return nil, err
+	}
+
+	repos := make(map[string]*UserRepoAccess)
+	grant := func(repo string, g *AccessGrant) {
+		r, ok := repos[repo]
+		if !ok {
+			r = &UserRepoAccess{Repo: repo}
+			repos[repo] = r
+		}
+		r.Grants = append(r.Grants, g)
+		if accessRank(g.Permission) > accessRank(r.Permission) {
+			r.Permission = g.Permission
+		}
+	}
+
+	if report.OrgRole != "" {
+		teams, warnings, err := ghs.userTeams(ctx, org, login)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list teams: %v", err))
+		}
+		report.Warnings = append(report.Warnings, warnings...)
+		for _, t := range teams {
+			report.Teams = append(report.Teams, &UserTeamMembership{Slug: t.team.GetSlug(), Name: t.team.GetName(), Role: t.role})
+			teamRepos, err := ghs.listTeamRepos(ctx, org, t.team.GetSlug())
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list repositories of %s: %v", t.team.GetSlug(), err))
+				continue
+			}
+			for _, r := range teamRepos {
+				grant(r.GetFullName(), &AccessGrant{Source: "team", Path: "team: " + t.team.GetSlug(), Permission: repositoryPermission(r)})
+			}
+		}
+	}
+
+	direct, err := ghs.userDirectGrants(ctx, org, login)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range direct {
+		if d.err != nil {
+			r, ok := repos[d.repo.GetFullName()]
+			if !ok {
+				r = &UserRepoAccess{Repo: d.repo.GetFullName()}
+				repos[d.repo.GetFullName()] = r
+			}
+			r.Error = fmt.Sprintf("failed to check direct access: %v", d.err)
+		}
+		if d.permission != "" {
+			grant(d.repo.GetFullName(), &AccessGrant{Source: "direct", Path: "direct collaborator", Permission: d.permission})
+			if report.OrgRole == "" {
+				report.OrgRole = "outside"
+			}
+		}
+		if d.invitation != nil {
+			report.Invitations = append(report.Invitations, &UserPendingInvitation{
+				Kind:       "repo",
+				Repo:       d.repo.GetFullName(),
+				ID:         d.invitation.GetID(),
+				Inviter:    d.invitation.GetInviter().GetLogin(),
+				CreatedAt:  d.invitation.GetCreatedAt().Time,
+				Permission: normalizePermission(d.invitation.GetPermissions()),
+			})
+		}
+	}
+
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		invitations, resp, err := ghs.client.Organizations.ListPendingOrgInvitations(ctx, org, opt)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list org invitations: %v", err))
+			break
+		}
+		for _, inv := range invitations {
+			if inv.GetLogin() == login {
+				report.Invitations = append(report.Invitations, &UserPendingInvitation{
+					Kind:      "org",
+					ID:        inv.GetID(),
+					Role:      inv.GetRole(),
+					Inviter:   inv.GetInviter().GetLogin(),
+					CreatedAt: inv.GetCreatedAt().Time,
+				})
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	for _, r := range repos {
+		report.Repos = append(report.Repos, r)
+	}
+	sort.Slice(report.Repos, func(i, j int) bool { return report.Repos[i].Repo < report.Repos[j].Repo })
+	return report, nil
+}
+
+// RevokeAllDirectAccess removes a user as a direct collaborator from every
+// repository of the org and cancels their pending repository invitations.
+// Team memberships are left untouched. Repositories that couldn't be checked
+// are returned as failed "lookup" results.
+func (ghs *GitHubService) RevokeAllDirectAccess(org, login string) ([]*RevokeAccessResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	direct, err := ghs.userDirectGrants(ctx, org, login)
+	if err != nil {
+		return nil, err
+	}
+	var results []*RevokeAccessResult
+	for _, d := range direct {
+		if d.err != nil {
+			results = append(results, &RevokeAccessResult{Repo: d.repo.GetFullName(), Kind: "lookup", Error: d.err.Error()})
+		}
+		if d.permission != "" {
+			result := &RevokeAccessResult{Repo: d.repo.GetFullName(), Kind: "collaborator", Permission: d.permission}
+			if _, err := ghs.client.Repositories.RemoveCollaborator(ctx, org, d.repo.GetName(), login); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+			results = append(results, result)
+		}
+		if d.invitation != nil {
+			result := &RevokeAccessResult{Repo: d.repo.GetFullName(), Kind: "invitation", Permission: normalizePermission(d.invitation.GetPermissions())}
+			if _, err := ghs.client.Repositories.DeleteInvitation(ctx, org, d.repo.GetName(), d.invitation.GetID()); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+type userTeam struct {
+	team *github.Team
+	role string
+}
+
+// userTeams returns the org teams the user belongs to, directly or through a
+// child team. Teams whose membership couldn't be checked are returned as
+// warnings.
+func (ghs *GitHubService) userTeams(ctx context.Context, org, login string) ([]*userTeam, []string, error) {
+	teams, err := ghs.listOrgTeams(ctx, org)
+	if err != nil {
+		return nil, nil, err
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	var result []*userTeam
+	var warnings []string
+	for _, t := range teams {
+		wg.Add(1)
+		go func(team *github.Team) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m, resp, err := ghs.client.Teams.GetTeamMembershipBySlug(ctx, org, team.GetSlug(), login)
+			if err != nil {
+				if resp == nil || resp.StatusCode != http.StatusNotFound {
+					mu.Lock()
+					warnings = append(warnings, fmt.Sprintf("failed to check membership of %s: %v", team.GetSlug(), err))
+					mu.Unlock()
+				}
+				return
+			}
+			if m.GetState() != "active" {
+				return
+			}
+			mu.Lock()
+			result = append(result, &userTeam{team: team, role: m.GetRole()})
+			mu.Unlock()
+		}(t)
+	}
+	wg.Wait()
+	sort.Slice(result, func(i, j int) bool { return result[i].team.GetSlug() < result[j].team.GetSlug() })
+	sort.Strings(warnings)
+	return result, warnings, nil
+}
+
+// userDirectGrants scans every repository of the org for direct collaborator
+// grants and pending invitations addressed to the user. Repositories that
+// couldn't be scanned are included with err set.
+func (ghs *GitHubService) userDirectGrants(ctx context.Context, org, login string) ([]*userRepoGrants, error) {
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	var result []*userRepoGrants
+	for _, r := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			grants := &userRepoGrants{repo: repo}
+			if users, err := ghs.listCollaboratorUsers(ctx, org, repo.GetName(), "direct"); err == nil {
+				for _, u := range users {
+					if u.GetLogin() == login {
+						grants.permission = normalizePermission(collaboratorPermission(u))
+					}
+				}
+			} else {
+				grants.err = fmt.Errorf("collaborators: %w", err)
+			}
+			if invitations, err := ghs.listRepoInvitations(ctx, org, repo.GetName()); err == nil {
+				for _, inv := range invitations {
+					if inv.GetInvitee().GetLogin() == login {
+						grants.invitation = inv
+					}
+				}
+			} else {
+				grants.err = errors.Join(grants.err, fmt.Errorf("invitations: %w", err))
+			}
+			if grants.permission == "" && grants.invitation == nil && grants.err == nil {
+				return
+			}
+			mu.Lock()
+			result = append(result, grants)
+			mu.Unlock()
+		}(r)
+	}
+	wg.Wait()
+	sort.Slice(result, func(i, j int) bool { return result[i].repo.GetFullName() < result[j].repo.GetFullName() })
+	return result, nil
+}
+
+// repositoryPermission returns the highest permission in a repository's
+// permissions map, as returned when listing a team's repositories.
+func repositoryPermission(r *github.Repository) string {
+	if role := r.GetRoleName(); role != "" {
+		return normalizePermission(role)
+	}
+	for _, p := range []string{"admin", "maintain", "push", "triage", "pull"} {
+		if r.Permissions[p] {
+			return normalizePermission(p)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type VisibilityImpact struct {
+	Code   string   `json:"code"` // "forks_detached", "forks_deleted", "actions_secrets", "pages", "outside_collaborators" or "unverified"
+	Detail string   `json:"detail"`
+	Items  []string `json:"items"`
+}
+
+type VisibilityAnalysis struct {
+	Repo    string              `json:"repo"`
+	Current string              `json:"current"`
+	Target  string              `json:"target"`
+	Impacts []*VisibilityImpact `json:"impacts"`
+	Error   string              `json:"error"`
+}
+
+type VisibilityChangeResult struct {
+	Repo    string              `json:"repo"`
+	Success bool                `json:"success"`
+	Impacts []*VisibilityImpact `json:"impacts"`
+	Error   string              `json:"error"`
+	Unmet   []string            `json:"unmet"` // Impact codes that were not acknowledged
+}
+
+// AnalyzeVisibilityChange lists, for each repository, what changing it to
+// the target visibility would affect.
+func (ghs *GitHubService) AnalyzeVisibilityChange(fullRepos []string, visibility string) ([]*VisibilityAnalysis, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !isValidVisibility(visibility) {
+		return nil, fmt.Errorf("invalid visibility %q", visibility)
+	}
+	ctx := context.Background()
+	var analyses []*VisibilityAnalysis
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			analyses = append(analyses, &VisibilityAnalysis{Repo: fullRepo, Target: visibility, Error: "invalid repository name"})
+			continue
+		}
+		analyses = append(analyses, ghs.analyzeVisibilityChange(ctx, parts[0], parts[1], visibility))
+	}
+	return analyses, nil
+}
+
+// ChangeRepoVisibility changes a repository's visibility. The impacts are
+// re-analysed first and every impact code must be listed in acknowledged,
+// otherwise nothing is changed.
+func (ghs *GitHubService) ChangeRepoVisibility(owner, repo, visibility string, acknowledged []string) (*VisibilityChangeResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !isValidVisibility(visibility) {
+		return nil, fmt.Errorf("invalid visibility %q", visibility)
+	}
+	ctx := context.Background()
+	result := ghs.changeRepoVisibility(ctx, owner, repo, visibility, acknowledged)
+	ghs.RefreshRepoList(owner)
+	return result, nil
+}
+
+// BulkChangeRepoVisibility changes many repositories at once. acknowledged
+// maps each full repository name to the impact codes confirmed for it.
+func (ghs *GitHubService) BulkChangeRepoVisibility(fullRepos []string, visibility string, acknowledged map[string][]string) ([]*VisibilityChangeResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if !isValidVisibility(visibility) {
+		return nil, fmt.Errorf("invalid visibility %q", visibility)
+	}
+	ctx := context.Background()
+	var results []*VisibilityChangeResult
+	owners := make(map[string]bool)
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &VisibilityChangeResult{Repo: fullRepo, Error: "invalid repository name"})
+			continue
+		}
+		owners[parts[0]] = true
+		results = append(results, ghs.changeRepoVisibility(ctx, parts[0], parts[1], visibility, acknowledged[fullRepo]))
+	}
+	for owner := range owners {
+		ghs.RefreshRepoList(owner)
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) changeRepoVisibility(ctx context.Context, owner, repo, visibility string, acknowledged []string) *VisibilityChangeResult {
+	analysis := ghs.analyzeVisibilityChange(ctx, owner, repo, visibility)
+	result := &VisibilityChangeResult{Repo: analysis.Repo, Impacts: analysis.Impacts}
+	if analysis.Error != "" {
+		result.Error = analysis.Error
+		return result
+	}
+	if analysis.Current == visibility {
+		result.Success = true
+		return result
+	}
+	for _, impact := range analysis.Impacts {
+		if !slices.Contains(acknowledged, impact.Code) {
+			result.Unmet = append(result.Unmet, impact.Code)
+		}
+	}
+	if len(result.Unmet) > 0 {
+		result.Error = "not all impacts were acknowledged"
+		return result
+	}
+	_, _, err := ghs.client.Repositories.Edit(ctx, owner, repo, &github.Repository{Visibility: github.Ptr(visibility)})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+func (ghs *GitHubService) analyzeVisibilityChange(ctx context.Context, owner, repo, visibility string) *VisibilityAnalysis {
+	analysis := &VisibilityAnalysis{Repo: owner + "/" + repo, Target: visibility}
+	r, _, err := ghs.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		analysis.Error = err.Error()
+		return analysis
+	}
+	analysis.Current = r.GetVisibility()
+	if analysis.Current == visibility {
+		return analysis
+	}
+
+	if r.GetForksCount() > 0 {
+		forks, err := ghs.listForks(ctx, owner, repo)
+		if err != nil {
+			analysis.Error = fmt.Sprintf("failed to list forks: %v", err)
+			return analysis
+		}
+		analysis.Impacts = append(analysis.Impacts, forkImpacts(analysis.Current, visibility, forks)...)
+	}
+
+	// A check that can't run is reported as its own impact so that it has to
+	// be acknowledged like a known risk.
+	var unverified []string
+	secrets, err := ghs.listActionsSecrets(ctx, &actionsScope{owner: owner, repo: repo})
+	if err != nil {
+		unverified = append(unverified, fmt.Sprintf("Actions secrets: %v", err))
+	} else if len(secrets) > 0 {
+		var names []string
+		for _, s := range secrets {
+			names = append(names, s.Name)
+		}
+		detail := fmt.Sprintf("%d Actions secrets are defined", len(secrets))
+		if visibility == "public" {
+			detail += "; workflows triggered from forks may be able to reach them through misconfigured workflows"
+		}
+		analysis.Impacts = append(analysis.Impacts, &VisibilityImpact{Code: "actions_secrets", Detail: detail, Items: names})
+	}
+
+	if r.GetHasPages() {
+		pages, _, err := ghs.client.Repositories.GetPagesInfo(ctx, owner, repo)
+		item := ""
+		if err == nil {
+			item = pages.GetHTMLURL()
+		}
+		detail := "the GitHub Pages site will change visibility with the repository"
+		if visibility != "public" {
+			detail = "the GitHub Pages site may be unpublished or become private, depending on the plan"
+		}
+		analysis.Impacts = append(analysis.Impacts, &VisibilityImpact{Code: "pages", Detail: detail, Items: []string{item}})
+	}
+
+	outside, err := ghs.listCollaboratorUsers(ctx, owner, repo, "outside")
+	if err != nil {
+		unverified = append(unverified, fmt.Sprintf("outside collaborators: %v", err))
+	} else if len(outside) > 0 {
+		var logins []string
+		for _, u := range outside {
+			logins = append(logins, u.GetLogin())
+		}
+		analysis.Impacts = append(analysis.Impacts, &VisibilityImpact{
+			Code:   "outside_collaborators",
+			Detail: fmt.Sprintf("%d outside collaborators have direct access", len(logins)),
+			Items:  logins,
+		})
+	}
+
+	if len(unverified) > 0 {
+		analysis.Impacts = append(analysis.Impacts, &VisibilityImpact{
+			Code:   "unverified",
+			Detail: "some impacts could not be checked",
+			Items:  unverified,
+		})
+	}
+	return analysis
+}
+
+// forkImpacts describes what happens to existing forks when a repository
+// changes from current to target visibility. Public forks are detached when
+// a public repository is made private or internal, and private forks are
+// detached into standalone private repositories when a private repository is
+// made public. Making an internal repository private deletes forks owned by
+// personal accounts.
+func forkImpacts(current, target string, forks []*github.Repository) []*VisibilityImpact {
+	if len(forks) == 0 || current == target {
+		return nil
+	}
+	switch {
+	case current == "public":
+		return []*VisibilityImpact{{
+			Code:   "forks_detached",
+			Detail: fmt.Sprintf("%d public forks will be detached into a separate network", len(forks)),
+			Items:  repoFullNames(forks),
+		}}
+	case current == "private" && target == "public":
+		return []*VisibilityImpact{{
+			Code:   "forks_detached",
+			Detail: fmt.Sprintf("%d private forks will be detached into standalone private repositories", len(forks)),
+			Items:  repoFullNames(forks),
+		}}
+	case current == "internal" && target == "private":
+		var personal []*github.Repository
+		for _, f := range forks {
+			if f.GetOwner().GetType() == "User" {
+				personal = append(personal, f)
+			}
+		}
+		if len(personal) == 0 {
+			return nil
+		}
+		return []*VisibilityImpact{{
+			Code:   "forks_deleted",
+			Detail: fmt.Sprintf("%d forks owned by personal accounts will be deleted", len(personal)),
+			Items:  repoFullNames(personal),
+		}}
+	}
+	return nil
+}
+
+func (ghs *GitHubService) listForks(ctx context.Context, owner, repo string) ([]*github.Repository, error) {
+	opt := &github.RepositoryListForksOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var all []*github.Repository
+	for {
+		forks, resp, err := ghs.client.Repositories.ListForks(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, forks...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func repoFullNames(repos []*github.Repository) []string {
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.GetFullName())
+	}
+	return names
+}
+
+func isValidVisibility(visibility string) bool {
+	return visibility == "public" || visibility == "private" || visibility == "internal"
+}
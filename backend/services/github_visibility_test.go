@@ -0,0 +1,65 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func TestForkImpacts(t *testing.T) {
+	forks := []*github.Repository{
+		{FullName: github.Ptr("alice/repo"), Owner: &github.User{Type: github.Ptr("User")}},
+		{FullName: github.Ptr("acme-labs/repo"), Owner: &github.User{Type: github.Ptr("Organization")}},
+	}
+
+	tests := []struct {
+		current   string
+		target    string
+		wantCode  string // Empty when forks are unaffected
+		wantItems []string
+	}{
+		{"public", "private", "forks_detached", []string{"alice/repo", "acme-labs/repo"}},
+		{"public", "internal", "forks_detached", []string{"alice/repo", "acme-labs/repo"}},
+		{"private", "public", "forks_detached", []string{"alice/repo", "acme-labs/repo"}},
+		{"internal", "private", "forks_deleted", []string{"alice/repo"}},
+		{"private", "internal", "", nil},
+		{"internal", "public", "", nil},
+		{"private", "private", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"_to_"+tt.target, func(t *testing.T) {
+			impacts := forkImpacts(tt.current, tt.target, forks)
+			if tt.wantCode == "" {
+				if len(impacts) != 0 {
+					t.Fatalf("got %d impacts, want none", len(impacts))
+				}
+				return
+			}
+			if len(impacts) != 1 {
+				t.Fatalf("got %d impacts, want 1", len(impacts))
+			}
+			if impacts[0].Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", impacts[0].Code, tt.wantCode)
+			}
+			if !slices.Equal(impacts[0].Items, tt.wantItems) {
+				t.Errorf("items = %v, want %v", impacts[0].Items, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestForkImpactsNoForks(t *testing.T) {
+	if impacts := forkImpacts("public", "private", nil); len(impacts) != 0 {
+		t.Fatalf("got %d impacts, want none", len(impacts))
+	}
+}
+
+func TestForkImpactsOrgForksOnly(t *testing.T) {
+	forks := []*github.Repository{
+		{FullName: github.Ptr("acme-labs/repo"), Owner: &github.User{Type: github.Ptr("Organization")}},
+	}
+	if impacts := forkImpacts("internal", "private", forks); len(impacts) != 0 {
+		t.Fatalf("got %d impacts, want none", len(impacts))
+	}
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+// GitHubWebhook is a repository webhook, or an org webhook when Repo is empty.
+type GitHubWebhook struct {
+	ID                 int64     `json:"id"`
+	Owner              string    `json:"owner"`
+	Repo               string    `json:"repo"`
+	URL                string    `json:"url"`
+	Host               string    `json:"host"`
+	ContentType        string    `json:"content_type"`
+	InsecureSSL        bool      `json:"insecure_ssl"`
+	Events             []string  `json:"events"`
+	Active             bool      `json:"active"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	LastResponseCode   int       `json:"last_response_code"`
+	LastResponseStatus string    `json:"last_response_status"`
+}
+
+// WebhookSpec describes a webhook to create or edit. Nil fields are left
+// unchanged on edit; the secret can be set but is never read back.
+type WebhookSpec struct {
+	URL         string   `json:"url"`
+	ContentType string   `json:"content_type"` // "json" or "form"
+	Secret      string   `json:"secret"`
+	InsecureSSL *bool    `json:"insecure_ssl"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+type GitHubWebhookDelivery struct {
+	ID          int64     `json:"id"`
+	GUID        string    `json:"guid"`
+	DeliveredAt time.Time `json:"delivered_at"`
+	Redelivery  bool      `json:"redelivery"`
+	Duration    float64   `json:"duration"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code"`
+	Event       string    `json:"event"`
+	Action      string    `json:"action"`
+}
+
+type WebhookHostGroup struct {
+	Host    string           `json:"host"`
+	Hooks   []*GitHubWebhook `json:"hooks"`
+	Failing int              `json:"failing"` // Hooks whose last delivery did not succeed
+}
+
+type WebhookReport struct {
+	Org      string              `json:"org"`
+	Groups   []*WebhookHostGroup `json:"groups"`
+	Warnings []string            `json:"warnings"`
+}
+
+type WebhookDeleteResult struct {
+	Owner   string `json:"owner"`
+	Repo    string `json:"repo"`
+	ID      int64  `json:"id"`
+	URL     string `json:"url"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error"`
+}
+
+// ListWebhooks lists the webhooks of a repository, or of the org when repo
+// is empty.
+func (ghs *GitHubService) ListWebhooks(owner, repo string) ([]*GitHubWebhook, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return ghs.listWebhooks(context.Background(), owner, repo)
+}
+
+func (ghs *GitHubService) CreateWebhook(owner, repo string, spec *WebhookSpec) (*GitHubWebhook, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if spec == nil || spec.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	ctx := context.Background()
+	hook := spec.toHook()
+	if hook.Active == nil {
+		hook.Active = github.Ptr(true)
+	}
+	if len(hook.Events) == 0 {
+		hook.Events = []string{"push"}
+	}
+	var created *github.Hook
+	var err error
+	if repo == "" {
+		created, _, err = ghs.client.Organizations.CreateHook(ctx, owner, hook)
+	} else {
+		created, _, err = ghs.client.Repositories.CreateHook(ctx, owner, repo, hook)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return webhookFromHook(owner, repo, created), nil
+}
+
+func (ghs *GitHubService) UpdateWebhook(owner, repo string, id int64, spec *WebhookSpec) (*GitHubWebhook, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("no changes given")
+	}
+	ctx := context.Background()
+	// Editing the hook replaces its whole config, so config changes go through
+	// the config endpoint, which merges them.
+	if config := spec.hookConfig(); config != nil {
+		var err error
+		if repo == "" {
+			_, _, err = ghs.client.Organizations.EditHookConfiguration(ctx, owner, id, config)
+		} else {
+			_, _, err = ghs.client.Repositories.EditHookConfiguration(ctx, owner, repo, id, config)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	var updated *github.Hook
+	var err error
+	switch {
+	case spec.Events == nil && spec.Active == nil && repo == "":
+		updated, _, err = ghs.client.Organizations.GetHook(ctx, owner, id)
+	case spec.Events == nil && spec.Active == nil:
+		updated, _, err = ghs.client.Repositories.GetHook(ctx, owner, repo, id)
+	case repo == "":
+		updated, _, err = ghs.client.Organizations.EditHook(ctx, owner, id, &github.Hook{Events: spec.Events, Active: spec.Active})
+	default:
+		updated, _, err = ghs.client.Repositories.EditHook(ctx, owner, repo, id, &github.Hook{Events: spec.Events, Active: spec.Active})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return webhookFromHook(owner, repo, updated), nil
+}
+
+func (ghs *GitHubService) DeleteWebhook(owner, repo string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	return ghs.deleteWebhook(context.Background(), owner, repo, id)
+}
+
+// PingWebhook sends a ping event; the outcome shows up in the deliveries.
+func (ghs *GitHubService) PingWebhook(owner, repo string, id int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var err error
+	if repo == "" {
+		_, err = ghs.client.Organizations.PingHook(ctx, owner, id)
+	} else {
+		_, err = ghs.client.Repositories.PingHook(ctx, owner, repo, id)
+	}
+	return err
+}
+
+// ListWebhookDeliveries returns up to limit of the most recent deliveries of
+// a webhook.
+func (ghs *GitHubService) ListWebhookDeliveries(owner, repo string, id int64, limit int) ([]*GitHubWebhookDelivery, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	ctx := context.Background()
+	opt := &github.ListCursorOptions{PerPage: min(limit, 100)}
+	var all []*GitHubWebhookDelivery
+	for len(all) < limit {
+		var deliveries []*github.HookDelivery
+		var resp *github.Response
+		var err error
+		if repo == "" {
+			deliveries, resp, err = ghs.client.Organizations.ListHookDeliveries(ctx, owner, id, opt)
+		} else {
+			deliveries, resp, err = ghs.client.Repositories.ListHookDeliveries(ctx, owner, repo, id, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deliveries {
+			delivery := &GitHubWebhookDelivery{
+				ID:          d.GetID(),
+				GUID:        d.GetGUID(),
+				DeliveredAt: d.GetDeliveredAt().Time,
+				Redelivery:  d.GetRedelivery(),
+				Status:      d.GetStatus(),
+				StatusCode:  d.GetStatusCode(),
+				Event:       d.GetEvent(),
+				Action:      d.GetAction(),
+			}
+			if d.Duration != nil {
+				delivery.Duration = *d.Duration
+			}
+			all = append(all, delivery)
+		}
+		if resp.Cursor == "" {
+			break
+		}
+		opt.Cursor = resp.Cursor
+	}
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) RedeliverWebhookDelivery(owner, repo string, hookID, deliveryID int64) error {
+	if ghs.client == nil {
+		return fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var err error
+	if repo == "" {
+		_, _, err = ghs.client.Organizations.RedeliverHookDelivery(ctx, owner, hookID, deliveryID)
+	} else {
+		_, _, err = ghs.client.Repositories.RedeliverHookDelivery(ctx, owner, repo, hookID, deliveryID)
+	}
+	// Redelivery is queued and answered with 202 Accepted.
+	var accepted *github.AcceptedError
+	if errors.As(err, &accepted) {
+		return nil
+	}
+	return err
+}
+
+// GetWebhookReport collects the org's webhooks and those of all its
+// repositories, grouped by the host they deliver to.
+func (ghs *GitHubService) GetWebhookReport(org string) (*WebhookReport, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	report := &WebhookReport{Org: org}
+
+	var hooks []*GitHubWebhook
+	orgHooks, err := ghs.listWebhooks(ctx, org, "")
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list org webhooks: %v", err))
+	}
+	hooks = append(hooks, orgHooks...)
+
+	repos, err := ghs.listOwnerRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for _, r := range repos {
+		wg.Add(1)
+		go func(repo *github.Repository) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			repoHooks, err := ghs.listWebhooks(ctx, org, repo.GetName())
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", repo.GetFullName(), err))
+				return
+			}
+			hooks = append(hooks, repoHooks...)
+		}(r)
+	}
+	wg.Wait()
+
+	groups := make(map[string]*WebhookHostGroup)
+	for _, h := range hooks {
+		group, ok := groups[h.Host]
+		if !ok {
+			group = &WebhookHostGroup{Host: h.Host}
+			groups[h.Host] = group
+		}
+		group.Hooks = append(group.Hooks, h)
+		if webhookFailing(h) {
+			group.Failing++
+		}
+	}
+	for _, group := range groups {
+		sort.Slice(group.Hooks, func(i, j int) bool { return group.Hooks[i].Repo < group.Hooks[j].Repo })
+		report.Groups = append(report.Groups, group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool { return report.Groups[i].Host < report.Groups[j].Host })
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+// BulkDeleteWebhooks deletes the given webhooks, identified by Owner, Repo
+// and ID as returned by GetWebhookReport.
+func (ghs *GitHubService) BulkDeleteWebhooks(hooks []*GitHubWebhook) ([]*WebhookDeleteResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	var results []*WebhookDeleteResult
+	for _, h := range hooks {
+		result := &WebhookDeleteResult{Owner: h.Owner, Repo: h.Repo, ID: h.ID, URL: h.URL}
+		if err := ghs.deleteWebhook(ctx, h.Owner, h.Repo, h.ID); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Deleted = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (ghs *GitHubService) listWebhooks(ctx context.Context, owner, repo string) ([]*GitHubWebhook, error) {
+	opt := &github.ListOptions{PerPage: 100}
+	var all []*GitHubWebhook
+	for {
+		var hooks []*github.Hook
+		var resp *github.Response
+		var err error
+		if repo == "" {
+			hooks, resp, err = ghs.client.Organizations.ListHooks(ctx, owner, opt)
+		} else {
+			hooks, resp, err = ghs.client.Repositories.ListHooks(ctx, owner, repo, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range hooks {
+			all = append(all, webhookFromHook(owner, repo, h))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func (ghs *GitHubService) deleteWebhook(ctx context.Context, owner, repo string, id int64) error {
+	var err error
+	if repo == "" {
+		_, err = ghs.client.Organizations.DeleteHook(ctx, owner, id)
+	} else {
+		_, err = ghs.client.Repositories.DeleteHook(ctx, owner, repo, id)
+	}
+	return err
+}
+
+func (s *WebhookSpec) toHook() *github.Hook {
+	return &github.Hook{Events: s.Events, Active: s.Active, Config: s.hookConfig()}
+}
+
+// hookConfig returns the config fields set in the spec, or nil when none are.
+func (s *WebhookSpec) hookConfig() *github.HookConfig {
+	config := &github.HookConfig{}
+	if s.URL != "" {
+		config.URL = github.Ptr(s.URL)
+	}
+	if s.ContentType != "" {
+		config.ContentType = github.Ptr(s.ContentType)
+	}
+	if s.Secret != "" {
+		config.Secret = github.Ptr(s.Secret)
+	}
+	if s.InsecureSSL != nil {
+		config.InsecureSSL = github.Ptr("0")
+		if *s.InsecureSSL {
+			config.InsecureSSL = github.Ptr("1")
+		}
+	}
+	if *config == (github.HookConfig{}) {
+		return nil
+	}
+	return config
+}
+
+func webhookFromHook(owner, repo string, h *github.Hook) *GitHubWebhook {
+	hook := &GitHubWebhook{
+		ID:        h.GetID(),
+		Owner:     owner,
+		Repo:      repo,
+		Events:    h.Events,
+		Active:    h.GetActive(),
+		CreatedAt: h.GetCreatedAt().Time,
+		UpdatedAt: h.GetUpdatedAt().Time,
+	}
+	if c := h.Config; c != nil {
+		hook.URL = c.GetURL()
+		hook.ContentType = c.GetContentType()
+		hook.InsecureSSL = c.GetInsecureSSL() == "1"
+	}
+	if u, err := url.Parse(hook.URL); err == nil {
+		hook.Host = u.Hostname()
+	}
+	if code, ok := h.LastResponse["code"].(float64); ok {
+		hook.LastResponseCode = int(code)
+	}
+	if status, ok := h.LastResponse["status"].(string); ok {
+		hook.LastResponseStatus = status
+	}
+	return hook
+}
+
+// webhookFailing reports whether the last delivery of an active hook failed.
+// Hooks that were never delivered report status "unused".
+func webhookFailing(h *GitHubWebhook) bool {
+	if !h.Active {
+		return false
+	}
+	return h.LastResponseStatus != "" && h.LastResponseStatus != "active" && h.LastResponseStatus != "unused"
+}
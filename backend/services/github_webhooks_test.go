@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func TestWebhookFailing(t *testing.T) {
+	tests := []struct {
+		name   string
+		hook   *GitHubWebhook
+		failed bool
+	}{
+		{"last delivery ok", &GitHubWebhook{Active: true, LastResponseStatus: "active"}, false},
+		{"never delivered", &GitHubWebhook{Active: true, LastResponseStatus: "unused"}, false},
+		{"no status", &GitHubWebhook{Active: true}, false},
+		{"last delivery failed", &GitHubWebhook{Active: true, LastResponseStatus: "timeout", LastResponseCode: 502}, true},
+		{"inactive and failed", &GitHubWebhook{LastResponseStatus: "timeout"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := webhookFailing(tt.hook); got != tt.failed {
+				t.Errorf("webhookFailing() = %v, want %v", got, tt.failed)
+			}
+		})
+	}
+}
+
+func TestWebhookSpecHookConfig(t *testing.T) {
+	if config := (&WebhookSpec{Events: []string{"push"}, Active: github.Ptr(false)}).hookConfig(); config != nil {
+		t.Errorf("events-only spec produced config %v", config)
+	}
+
+	config := (&WebhookSpec{ContentType: "json", InsecureSSL: github.Ptr(false)}).hookConfig()
+	if config == nil {
+		t.Fatal("config is nil")
+	}
+	if config.URL != nil || config.Secret != nil {
+		t.Errorf("unset fields sent: url=%v secret=%v", config.URL, config.Secret)
+	}
+	if config.GetContentType() != "json" || config.GetInsecureSSL() != "0" {
+		t.Errorf("got content_type=%q insecure_ssl=%q", config.GetContentType(), config.GetInsecureSSL())
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+type RulesetTemplate struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Ruleset     *github.RepositoryRuleset `json:"ruleset"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+var rulesetTemplatesPath = filepath.Join(filepath.Dir(configPath), "ruleset_templates.json")
+
+func LoadRulesetTemplates() ([]*RulesetTemplate, error) {
+	var templates []*RulesetTemplate
+	data, err := os.ReadFile(rulesetTemplatesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templates, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(data, &templates)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func SaveRulesetTemplates(templates []*RulesetTemplate) error {
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(rulesetTemplatesPath), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(rulesetTemplatesPath, data, 0644)
+}
+
+func findRulesetTemplate(templates []*RulesetTemplate, name string) (int, *RulesetTemplate) {
+	for i, t := range templates {
+		if t.Name == name {
+			return i, t
+		}
+	}
+	return -1, nil
+}
+
+// sanitizeRuleset returns a deep copy of a ruleset without the server-assigned
+// fields, so it can be stored as a template and sent to a different target.
+func sanitizeRuleset(rs *github.RepositoryRuleset) (*github.RepositoryRuleset, error) {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return nil, err
+	}
+	clean := &github.RepositoryRuleset{}
+	err = json.Unmarshal(data, clean)
+	if err != nil {
+		return nil, err
+	}
+	clean.ID = nil
+	clean.Source = ""
+	clean.SourceType = nil
+	clean.CurrentUserCanBypass = nil
+	clean.NodeID = nil
+	clean.Links = nil
+	clean.CreatedAt = nil
+	clean.UpdatedAt = nil
+	return clean, nil
+}
+
+type RulesetTemplateParams struct {
+	IncludeRefs    []string              `json:"include_refs"`
+	ExcludeRefs    []string              `json:"exclude_refs"`
+	BypassActors   []*github.BypassActor `json:"bypass_actors"`
+	Enforcement    string                `json:"enforcement"`
+	UpdateExisting bool                  `json:"update_existing"`
+}
+
+type RulesetApplyResult struct {
+	Repo   string `json:"repo"`
+	Action string `json:"action"` // "created", "updated", "skipped" or "failed"
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+func (ghs *GitHubService) ListRulesetTemplates() ([]*RulesetTemplate, error) {
+	return LoadRulesetTemplates()
+}
+
+func (ghs *GitHubService) SaveRulesetTemplate(template *RulesetTemplate) error {
+	if template == nil || template.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if template.Ruleset == nil {
+		return fmt.Errorf("template ruleset is required")
+	}
+	ruleset, err := sanitizeRuleset(template.Ruleset)
+	if err != nil {
+		return err
+	}
+	template.Ruleset = ruleset
+
+	templates, err := LoadRulesetTemplates()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	template.UpdatedAt = now
+	if idx, existing := findRulesetTemplate(templates, template.Name); existing != nil {
+		template.CreatedAt = existing.CreatedAt
+		templates[idx] = template
+	} else {
+		template.CreatedAt = now
+		templates = append(templates, template)
+	}
+	return SaveRulesetTemplates(templates)
+}
+
+func (ghs *GitHubService) DeleteRulesetTemplate(name string) error {
+	templates, err := LoadRulesetTemplates()
+	if err != nil {
+		return err
+	}
+	idx, _ := findRulesetTemplate(templates, name)
+	if idx == -1 {
+		return fmt.Errorf("template %q not found", name)
+	}
+	templates = append(templates[:idx], templates[idx+1:]...)
+	return SaveRulesetTemplates(templates)
+}
+
+// CreateRulesetTemplateFromRepo stores an existing repository ruleset as a
+// named template.
+func (ghs *GitHubService) CreateRulesetTemplateFromRepo(owner, repo string, rulesetID int64, name, description string) (*RulesetTemplate, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ctx := context.Background()
+	ruleset, _, err := ghs.client.Repositories.GetRuleset(ctx, owner, repo, rulesetID, false)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = ruleset.Name
+	}
+	template := &RulesetTemplate{
+		Name:        name,
+		Description: description,
+		Ruleset:     ruleset,
+	}
+	err = ghs.SaveRulesetTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// buildRulesetFromTemplate applies the parameters to a copy of the template
+// ruleset. Empty parameters keep the values stored in the template.
+func buildRulesetFromTemplate(template *RulesetTemplate, params *RulesetTemplateParams) (*github.RepositoryRuleset, error) {
+	ruleset, err := sanitizeRuleset(template.Ruleset)
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		return ruleset, nil
+	}
+	if len(params.IncludeRefs) > 0 || len(params.ExcludeRefs) > 0 {
+		if ruleset.Conditions == nil {
+			ruleset.Conditions = &github.RepositoryRulesetConditions{}
+		}
+		ruleset.Conditions.RefName = &github.RepositoryRulesetRefConditionParameters{
+			Include: params.IncludeRefs,
+			Exclude: params.ExcludeRefs,
+		}
+		if ruleset.Conditions.RefName.Include == nil {
+			ruleset.Conditions.RefName.Include = []string{}
+		}
+		if ruleset.Conditions.RefName.Exclude == nil {
+			ruleset.Conditions.RefName.Exclude = []string{}
+		}
+	}
+	if params.BypassActors != nil {
+		ruleset.BypassActors = params.BypassActors
+	}
+	if params.Enforcement != "" {
+		ruleset.Enforcement = github.RulesetEnforcement(params.Enforcement)
+	}
+	return ruleset, nil
+}
+
+// templateRuleset loads a stored template and builds its ruleset.
+func templateRuleset(name string, params *RulesetTemplateParams) (*github.RepositoryRuleset, error) {
+	templates, err := LoadRulesetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	_, template := findRulesetTemplate(templates, name)
+	if template == nil {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	return buildRulesetFromTemplate(template, params)
+}
+
+// ApplyRulesetTemplate creates the templated ruleset on each repository. A
+// repository ruleset with the same name is updated when UpdateExisting is set
+// and skipped otherwise.
+func (ghs *GitHubService) ApplyRulesetTemplate(name string, fullRepos []string, params *RulesetTemplateParams) ([]*RulesetApplyResult, error) {
+	if ghs.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	ruleset, err := templateRuleset(name, params)
+	if err != nil {
+		return nil, err
+	}
+	updateExisting := params != nil && params.UpdateExisting
+
+	var results []*RulesetApplyResult
+	for _, fullRepo := range fullRepos {
+		parts := strings.Split(fullRepo, "/")
+		if len(parts) != 2 {
+			results = append(results, &RulesetApplyResult{Repo: fullRepo, Action: "skipped", Reason: "invalid repository name"})
+			continue
+		}
+		results = append(results, ghs.applyRuleset(parts[0], parts[1], ruleset, updateExisting))
+	}
+	return results, nil
+}
+
+// applyRuleset creates a repository ruleset, or updates the repository's own
+// ruleset with the same name when updateExisting is set.
+func (ghs *GitHubService) applyRuleset(owner, repo string, ruleset *github.RepositoryRuleset, updateExisting bool) *RulesetApplyResult {
+	ctx := context.Background()
+	result := &RulesetApplyResult{Repo: owner + "/" + repo}
+
+	existing, err := ghs.findRepoRulesetByName(ctx, owner, repo, ruleset.Name)
+	if err != nil {
+		result.Action = "skipped"
+		result.Error = err.Error()
+		return result
+	}
+
+	if existing != nil {
+		if !updateExisting {
+			result.Action = "skipped"
+			result.Reason = "a ruleset with this name already exists"
+			return result
+		}
+		_, _, err = ghs.client.Repositories.UpdateRuleset(ctx, owner, repo, existing.GetID(), *ruleset)
+	} else {
+		_, _, err = ghs.client.Repositories.CreateRuleset(ctx, owner, repo, *ruleset)
+	}
+	switch {
+	case err != nil:
+		result.Action = "failed"
+		result.Error = err.Error()
+	case existing != nil:
+		result.Action = "updated"
+	default:
+		result.Action = "created"
+	}
+	return result
+}
+
+// findRepoRulesetByName looks for a ruleset defined on the repository itself,
+// ignoring rulesets inherited from the organization.
+func (ghs *GitHubService) findRepoRulesetByName(ctx context.Context, owner, repo, name string) (*github.RepositoryRuleset, error) {
+	rulesets, _, err := ghs.client.Repositories.GetAllRulesets(ctx, owner, repo, &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(false),
+		ListOptions:     github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range rulesets {
+		if rs.Name == name {
+			return rs, nil
+		}
+	}
+	return nil, nil
+}
@@ -45,6 +45,9 @@ const App = () => {
     const reposUpdated = Events.On('github:repos:updated', (e) => {
       appDispatch({type: 'UPDATE_REPO_LIST', payload: {org: e.data.org, repos: e.data.repos}});
     });
+    const repoUpdated = Events.On('github:repo:updated', (e) => {
+      appDispatch({type: 'UPDATE_REPO_FIELDS', payload: {org: e.data.org, fullName: e.data.full_name, fields: e.data.fields}});
+    });
     const teamsUpdated = Events.On('github:teams:updated', (e) => {
       appDispatch({type: 'UPDATE_TEAM_LIST', payload: {org: e.data.org, teams: e.data.teams}});
     });
@@ -55,6 +58,9 @@ const App = () => {
       if (e.data && e.data.theme) {
         setColorScheme(e.data.theme === 'system' ? 'auto' : e.data.theme);
       }
+      if (e.data && e.data.repo_groups) {
+        appDispatch({type: 'UPDATE_REPO_GROUPS', payload: e.data.repo_groups});
+      }
     });
     const fetchError = Events.On('github:fetch:error', (e) => {
       appDispatch({type: 'SET_FETCH_ERROR', payload: e.data});
@@ -62,6 +68,7 @@ const App = () => {
 
     return () => {
       reposUpdated();
+      repoUpdated();
       teamsUpdated();
       statusUpdated();
       configUpdated();
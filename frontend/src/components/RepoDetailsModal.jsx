@@ -49,7 +49,8 @@ import {
     UpdateRepoCustomProperties, 
     DeleteBranchProtection, 
     DeleteRepoRuleset,
-    GetOrgCustomPropertyDefinitions
+    GetOrgCustomPropertyDefinitions,
+    GetRepoInheritedTeams
 } from '../../bindings/github.com/amnuts/github-admin/backend/services/githubservice.js';
 import {SaveConfig, GetConfig} from "../../bindings/github.com/amnuts/github-admin/backend/services/appconfigservice.js";
 
@@ -73,6 +74,23 @@ const RepoDetailsModal = ({ opened, onClose, details, loading, onRefresh, org })
     const [selectedTeamSlug, setSelectedTeamSlug] = useState('');
     const [selectedPermission, setSelectedPermission] = useState('push');
 
+    // Teams inherited through parent teams, loaded when the teams tab opens
+    const [inheritedTeams, setInheritedTeams] = useState([]);
+    const [inheritedTeamsError, setInheritedTeamsError] = useState('');
+
+    useEffect(() => {
+        if (!opened || !details || activeTab !== 'teams') return;
+        const [owner, name] = details.full_name.split('/');
+        setInheritedTeamsError('');
+        GetRepoInheritedTeams(owner, name)
+            .then(teams => setInheritedTeams(teams || []))
+            .catch(error => {
+                console.error(error);
+                setInheritedTeams([]);
+                setInheritedTeamsError(String(error));
+            });
+    }, [details, opened, activeTab]);
+
     useEffect(() => {
         if (details) {
             setTopics(details.topics || []);
@@ -382,6 +400,40 @@ const RepoDetailsModal = ({ opened, onClose, details, loading, onRefresh, org })
                                         )}
                                     </Table.Tbody>
                                 </Table>
+
+                                {inheritedTeamsError && (
+                                    <Text size="sm" c="red">Failed to load teams inherited through parent teams: {inheritedTeamsError}</Text>
+                                )}
+                                {inheritedTeams.length > 0 && (
+                                    <>
+                                        <Divider label="Inherited through parent teams" labelPosition="center" />
+                                        <Table verticalSpacing="sm">
+                                            <Table.Thead>
+                                                <Table.Tr>
+                                                    <Table.Th>Team</Table.Th>
+                                                    <Table.Th>Permission</Table.Th>
+                                                    <Table.Th>Inherited From</Table.Th>
+                                                </Table.Tr>
+                                            </Table.Thead>
+                                            <Table.Tbody>
+                                                {inheritedTeams.map(team => (
+                                                    <Table.Tr key={`${team.slug}:${team.inherited_from}`}>
+                                                        <Table.Td>
+                                                            <Text fw={500}>{team.name}</Text>
+                                                            <Text size="xs" c="dimmed">{team.slug}</Text>
+                                                        </Table.Td>
+                                                        <Table.Td>
+                                                            <Badge variant="outline">{team.permission}</Badge>
+                                                        </Table.Td>
+                                                        <Table.Td>
+                                                            <Text size="sm">{team.inherited_from}</Text>
+                                                        </Table.Td>
+                                                    </Table.Tr>
+                                                ))}
+                                            </Table.Tbody>
+                                        </Table>
+                                    </>
+                                )}
                             </Stack>
                         </Tabs.Panel>
 
@@ -498,7 +550,7 @@ const RepoDetailsModal = ({ opened, onClose, details, loading, onRefresh, org })
 
                         <Tabs.Panel value="rulesets">
                             <Stack gap="md">
-                                {details.rulesets?.map(rs => (
+                                {details.rulesets?.filter(rs => !rs.source_type || rs.source_type === 'Repository').map(rs => (
                                     <Paper key={rs.id} withBorder p="md" radius="md">
                                         <Group justify="space-between" mb="xs">
                                             <Group gap="xs">
@@ -543,6 +595,43 @@ const RepoDetailsModal = ({ opened, onClose, details, loading, onRefresh, org })
                                 {!details.rulesets?.length && (
                                     <Text ta="center" c="dimmed" py="xl">No rulesets found.</Text>
                                 )}
+
+                                {details.inherited_rules?.length > 0 && (
+                                    <>
+                                        <Divider label="Inherited from organization or enterprise" labelPosition="center" />
+                                        <Table verticalSpacing="sm">
+                                            <Table.Thead>
+                                                <Table.Tr>
+                                                    <Table.Th>Ruleset</Table.Th>
+                                                    <Table.Th>Source</Table.Th>
+                                                    <Table.Th>Rules</Table.Th>
+                                                </Table.Tr>
+                                            </Table.Thead>
+                                            <Table.Tbody>
+                                                {details.inherited_rules.map(rs => (
+                                                    <Table.Tr key={rs.id}>
+                                                        <Table.Td>
+                                                            <Group gap="xs">
+                                                                <Text fw={500}>{rs.name}</Text>
+                                                                <Badge size="xs" variant="outline">{rs.enforcement}</Badge>
+                                                            </Group>
+                                                        </Table.Td>
+                                                        <Table.Td>
+                                                            <Badge variant="light" color="gray">{rs.source_type}: {rs.source}</Badge>
+                                                        </Table.Td>
+                                                        <Table.Td>
+                                                            <Group gap={4}>
+                                                                {rs.rule_types?.map(type => (
+                                                                    <Badge key={type} variant="light" size="xs">{type}</Badge>
+                                                                ))}
+                                                            </Group>
+                                                        </Table.Td>
+                                                    </Table.Tr>
+                                                ))}
+                                            </Table.Tbody>
+                                        </Table>
+                                    </>
+                                )}
                             </Stack>
                         </Tabs.Panel>
 